@@ -0,0 +1,76 @@
+// Package applier shows themes in alacritty. Each Applier targets one way of
+// doing so, from editing alacritty.toml to recoloring running windows, and
+// New picks one by name, so that commands and the picker need not know
+// which.
+package applier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+// Capabilities tell how far an applied theme reaches.
+type Capabilities struct {
+	// Persistent themes are written to files, so that new windows and
+	// restarts of alacritty show them too
+	Persistent bool
+	// AllWindows is true if every running window shows the theme, rather
+	// than only the terminal or window the applier targets
+	AllWindows bool
+	// Imported is true if the alacritty config imports the theme's file,
+	// so that it.GetCurrentTheme reports it
+	Imported bool
+}
+
+// Applier shows themes in alacritty.
+type Applier interface {
+	// Apply shows theme.
+	Apply(theme it.ThemeData) error
+	// Revert drops what Apply did: persistent appliers restore what the
+	// files held before the first Apply, the others the colors of the
+	// alacritty config.
+	Revert() error
+	Capabilities() Capabilities
+}
+
+// Default is the name of the applier used if neither a command nor the
+// [apply] config section name one.
+const Default = "import"
+
+// constructors make the appliers by name. A new target only needs an entry.
+var constructors = map[string]func(config cf.Config) Applier{
+	"import": func(config cf.Config) Applier { return NewImport(config) },
+	"inline": func(config cf.Config) Applier { return NewInline(config) },
+	"osc":    func(config cf.Config) Applier { return NewOSC() },
+	"ipc":    func(config cf.Config) Applier { return NewIPC() },
+}
+
+// Names returns the names of the appliers.
+func Names() []string {
+	var names []string
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the applier named name, or if name is empty the one of the
+// [apply] config section.
+func New(config cf.Config, name string) (Applier, error) {
+	if name == "" {
+		name = config.Apply.Backend
+	}
+	if name == "" {
+		name = Default
+	}
+	newApplier, ok := constructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	return newApplier(config), nil
+}
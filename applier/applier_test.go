@@ -0,0 +1,237 @@
+package applier
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/ipc"
+	"goalacritty_themes/palette"
+	it "goalacritty_themes/theme_tools"
+)
+
+// setup returns a config with an alacritty.toml importing the first of two
+// installed themes, and the themes.
+func setup(t *testing.T) (cf.Config, []it.ThemeData) {
+	dir := t.TempDir()
+	themesDir := filepath.Join(dir, "themes", "themes")
+	require.NoError(t, os.MkdirAll(themesDir, 0755))
+	var themes []it.ThemeData
+	for _, name := range []string{"night", "paper"} {
+		path := filepath.Join(themesDir, name+".toml")
+		background := map[string]string{"night": "#1e1e2e", "paper": "#eeeeee"}[name]
+		content := "[colors.primary]\nbackground = '" + background + "'\nforeground = '#808080'\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		p, err := palette.Parse([]byte(content))
+		require.NoError(t, err)
+		themes = append(themes, it.ThemeData{Name: name, FullPath: path, Palette: p})
+	}
+
+	var config cf.Config
+	config.Paths.ThemesDirectory = filepath.Join(dir, "themes")
+	config.Paths.AlacrittyConfigPath = filepath.Join(dir, "alacritty.toml")
+	content := "import = [\n\"" + themes[0].FullPath + "\"\n]\n\n[font]\nsize = 11\n"
+	require.NoError(t, os.WriteFile(config.Paths.AlacrittyConfigPath, []byte(content), 0644))
+	return config, themes
+}
+
+func read(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// TestNew tests choosing the applier by name and by config
+func TestNew(t *testing.T) {
+	var config cf.Config
+	a, err := New(config, "")
+	assert.NoError(t, err)
+	assert.IsType(t, &Import{}, a)
+
+	config.Apply.Backend = "osc"
+	a, err = New(config, "")
+	assert.NoError(t, err)
+	assert.IsType(t, &OSC{}, a)
+	a, err = New(config, "ipc")
+	assert.NoError(t, err)
+	assert.IsType(t, &IPC{}, a)
+
+	_, err = New(config, "kitty")
+	assert.ErrorContains(t, err, "import, inline, ipc, osc")
+}
+
+// TestImport tests switching the import of alacritty.toml and reverting it
+func TestImport(t *testing.T) {
+	config, themes := setup(t)
+	a := NewImport(config)
+	assert.NoError(t, a.Revert(), "Reverting before applying should do nothing")
+
+	assert.NoError(t, a.Apply(themes[1]))
+	assert.Contains(t, read(t, config.Paths.AlacrittyConfigPath), themes[1].FullPath)
+	assert.NoError(t, a.Apply(themes[1]))
+	assert.NoError(t, a.Revert())
+	assert.Contains(t, read(t, config.Paths.AlacrittyConfigPath), themes[0].FullPath)
+	assert.NotContains(t, read(t, config.Paths.AlacrittyConfigPath), themes[1].FullPath)
+
+	assert.NoError(t, os.Remove(themes[1].FullPath))
+	assert.ErrorIs(t, a.Apply(themes[1]), it.ErrThemeMissing)
+}
+
+// TestInline tests writing the generated file and importing it last
+func TestInline(t *testing.T) {
+	config, themes := setup(t)
+	a := NewInline(config)
+	generated := filepath.Join(filepath.Dir(config.Paths.AlacrittyConfigPath), DefaultGeneratedFile)
+	assert.Equal(t, generated, a.Path())
+
+	assert.NoError(t, a.Apply(themes[1]))
+	colors := read(t, generated)
+	assert.Contains(t, colors, "[colors.primary]\nbackground = \"#eeeeee\"\nforeground = \"#808080\"\n")
+	assert.Contains(t, colors, "[colors.cursor]\ncursor = \"CellForeground\"\n")
+	assert.Contains(t, colors, "[colors.bright]\nblack = ")
+	parsed, err := palette.Parse([]byte(colors))
+	assert.NoError(t, err)
+	assert.Equal(t, *themes[1].Palette, *parsed)
+
+	config1 := read(t, config.Paths.AlacrittyConfigPath)
+	assert.Contains(t, config1, "import = [\n\""+themes[0].FullPath+"\",\n\""+generated+"\"\n]")
+	// The import is only added once
+	assert.NoError(t, a.Apply(themes[0]))
+	assert.Equal(t, config1, read(t, config.Paths.AlacrittyConfigPath))
+	assert.Contains(t, read(t, generated), "background = \"#1e1e2e\"")
+
+	// The generated file did not exist, so it is removed with its import
+	assert.NoError(t, a.Revert())
+	assert.NoFileExists(t, generated)
+	assert.NotContains(t, read(t, config.Paths.AlacrittyConfigPath), generated)
+
+	// Without an import list, one is added
+	assert.NoError(t, os.WriteFile(config.Paths.AlacrittyConfigPath, []byte("[font]\nsize = 11\n"), 0644))
+	assert.NoError(t, NewInline(config).Apply(themes[0]))
+	assert.True(t, strings.HasPrefix(read(t, config.Paths.AlacrittyConfigPath), "import = [\n\""+generated+"\"\n]\n\n[font]"))
+
+	assert.NoError(t, os.Remove(config.Paths.AlacrittyConfigPath))
+	assert.ErrorIs(t, NewInline(config).Apply(themes[0]), it.ErrConfigNotFound)
+}
+
+// TestInlineRevert tests that reverting leaves alacritty.toml and the
+// generated file as they were before the first Apply
+func TestInlineRevert(t *testing.T) {
+	config, themes := setup(t)
+	for _, original := range []string{
+		read(t, config.Paths.AlacrittyConfigPath),
+		"import = ['" + themes[0].FullPath + "'] # the theme\n[font]\nsize = 11\n",
+		"[font]\nsize = 11\n",
+	} {
+		require.NoError(t, os.WriteFile(config.Paths.AlacrittyConfigPath, []byte(original), 0644))
+		a := NewInline(config)
+		assert.NoError(t, a.Apply(themes[1]))
+		assert.NoError(t, a.Apply(themes[0]))
+		assert.NoError(t, a.Revert())
+		assert.Equal(t, original, read(t, config.Paths.AlacrittyConfigPath))
+		assert.NoFileExists(t, a.Path())
+	}
+
+	// A generated file which was already imported is restored and stays
+	// imported
+	generated := filepath.Join(filepath.Dir(config.Paths.AlacrittyConfigPath), DefaultGeneratedFile)
+	original := "import = [\"" + themes[0].FullPath + "\", \"" + generated + "\"]\n"
+	require.NoError(t, os.WriteFile(config.Paths.AlacrittyConfigPath, []byte(original), 0644))
+	require.NoError(t, os.WriteFile(generated, []byte("# kept\n"), 0644))
+	a := NewInline(config)
+	assert.NoError(t, a.Apply(themes[1]))
+	assert.NoError(t, a.Revert())
+	assert.Equal(t, original, read(t, config.Paths.AlacrittyConfigPath))
+	assert.Equal(t, "# kept\n", read(t, generated))
+}
+
+// TestImportAfterInline tests that switching back to the import backend
+// drops the import of the generated file, which would override the theme,
+// and that reverting brings it back
+func TestImportAfterInline(t *testing.T) {
+	config, themes := setup(t)
+	original := read(t, config.Paths.AlacrittyConfigPath)
+	inline := NewInline(config)
+	assert.NoError(t, inline.Apply(themes[1]))
+
+	a := NewImport(config)
+	assert.NoError(t, a.Apply(themes[1]))
+	assert.Equal(t, strings.Replace(original, themes[0].FullPath, themes[1].FullPath, 1), read(t, config.Paths.AlacrittyConfigPath))
+	assert.NotContains(t, read(t, config.Paths.AlacrittyConfigPath), inline.Path())
+	assert.NoError(t, a.Revert())
+	assert.Contains(t, read(t, config.Paths.AlacrittyConfigPath), "import = [\n\""+themes[0].FullPath+"\",\n\""+inline.Path()+"\"\n]")
+
+	// The generated file can be anywhere in a list on one line
+	oneLine := "import = ['" + inline.Path() + "', \"" + themes[0].FullPath + "\"]\n"
+	assert.NoError(t, os.WriteFile(config.Paths.AlacrittyConfigPath, []byte(oneLine), 0644))
+	assert.NoError(t, NewImport(config).Apply(themes[1]))
+	assert.Equal(t, "import = [\""+themes[1].FullPath+"\"]\n", read(t, config.Paths.AlacrittyConfigPath))
+}
+
+// TestInlineReset tests that resetting the inline backend shows the imported
+// theme again
+func TestInlineReset(t *testing.T) {
+	config, themes := setup(t)
+	original := read(t, config.Paths.AlacrittyConfigPath)
+	a := NewInline(config)
+	assert.NoError(t, a.Apply(themes[1]))
+	assert.NoError(t, NewInline(config).Reset())
+	assert.Equal(t, original, read(t, config.Paths.AlacrittyConfigPath))
+	assert.NoFileExists(t, a.Path())
+	// Nothing to reset
+	assert.NoError(t, NewInline(config).Reset())
+}
+
+// TestOSC tests the sequences written to the terminal
+func TestOSC(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "alacritty")
+	_, themes := setup(t)
+	var out bytes.Buffer
+	a := &OSC{Out: &out}
+
+	assert.NoError(t, a.Apply(themes[0]))
+	assert.Contains(t, out.String(), "\x1b]11;rgb:1e/1e/2e\a")
+	out.Reset()
+	assert.NoError(t, a.Revert())
+	assert.Contains(t, out.String(), "\x1b]111\a")
+	assert.Error(t, a.Apply(it.ThemeData{Name: "broken"}))
+	assert.False(t, a.Capabilities().Persistent)
+}
+
+// TestIPC tests recoloring a window over a fake alacritty socket
+func TestIPC(t *testing.T) {
+	_, themes := setup(t)
+	socket := filepath.Join(t.TempDir(), "Alacritty-test.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer l.Close()
+	lines := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			conn.Close()
+			lines <- line
+		}
+	}()
+	t.Setenv(ipc.SocketEnv, socket)
+	t.Setenv(ipc.WindowEnv, "7")
+
+	a := NewIPC()
+	assert.False(t, a.Capabilities().AllWindows)
+	assert.NoError(t, a.Apply(themes[1]))
+	assert.Contains(t, <-lines, `"window_id":7,"options":["colors.primary.background=\"#eeeeee\"",`)
+	assert.NoError(t, a.Revert())
+	assert.Contains(t, <-lines, `"reset":true`)
+}
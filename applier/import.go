@@ -0,0 +1,61 @@
+package applier
+
+import (
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+// Import points the import of alacritty.toml at the theme's file. Alacritty
+// reloads its config, so that every window follows. The file generated by
+// the inline backend would override the theme, so its import is dropped.
+type Import struct {
+	config   cf.Config
+	previous *it.ThemeData // imported before the first Apply, nil until then
+	inline   *Inline
+	dropped  bool // the import of the inline backend's file was dropped
+}
+
+// NewImport returns an Import applier.
+func NewImport(config cf.Config) *Import {
+	return &Import{config: config, inline: NewInline(config)}
+}
+
+func (a *Import) Apply(theme it.ThemeData) error {
+	if a.previous == nil {
+		previous, err := it.GetCurrentTheme(a.config)
+		if err != nil {
+			// Then there is nothing to restore
+			previous = &it.ThemeData{}
+		}
+		a.previous = previous
+	}
+	if err := it.CheckTheme(theme); err != nil {
+		return err
+	}
+	dropped, err := a.inline.dropImport()
+	if err != nil {
+		return err
+	}
+	a.dropped = a.dropped || dropped
+	return it.UpdateAlacrittyConfigFile(a.config, theme)
+}
+
+// Revert imports the theme imported before the first Apply again, and the
+// inline backend's file if Apply dropped it.
+func (a *Import) Revert() error {
+	if a.previous != nil && a.previous.FullPath != "" {
+		if err := it.UpdateAlacrittyConfigFile(a.config, *a.previous); err != nil {
+			return err
+		}
+	}
+	if a.dropped {
+		a.dropped = false
+		_, err := a.inline.ensureImport()
+		return err
+	}
+	return nil
+}
+
+func (a *Import) Capabilities() Capabilities {
+	return Capabilities{Persistent: true, AllWindows: true, Imported: true}
+}
@@ -0,0 +1,194 @@
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+// DefaultGeneratedFile is the name of the file the Inline applier writes,
+// next to alacritty.toml, unless the [apply] config section names another.
+const DefaultGeneratedFile = "goalacritty_colors.toml"
+
+// importListRe matches the import list of an alacritty config, capturing
+// its entries.
+var importListRe = regexp.MustCompile(`(?m)^\s*import\s*=\s*\[([^\]]*)\]`)
+
+// Inline writes the theme's colors to a generated file imported last by
+// alacritty.toml, so that they win over the imported theme. The colors are
+// copied, the theme's file is not needed afterwards.
+type Inline struct {
+	config   cf.Config
+	path     string
+	applied  bool
+	previous []byte // the generated file before the first Apply, nil if there was none
+	imported bool   // Apply added the generated file to the imports of alacritty.toml
+}
+
+// NewInline returns an Inline applier.
+func NewInline(config cf.Config) *Inline {
+	path := config.Apply.GeneratedFile
+	if path == "" {
+		path = filepath.Join(filepath.Dir(config.Paths.AlacrittyConfigPath), DefaultGeneratedFile)
+	}
+	return &Inline{config: config, path: path}
+}
+
+// Path returns the path of the generated file.
+func (a *Inline) Path() string {
+	return a.path
+}
+
+func (a *Inline) Apply(theme it.ThemeData) error {
+	if theme.Palette == nil {
+		return &it.ThemeError{Theme: theme.Name, Err: errors.New("the theme could not be parsed")}
+	}
+	if !a.applied {
+		previous, err := os.ReadFile(a.path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		a.previous, a.applied = previous, true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by goalacritty_themes from %s, changes are overwritten\n", theme.FullPath)
+	table := ""
+	for _, s := range theme.Palette.Settings() {
+		name, key, _ := strings.Cut(s.Key, ".")
+		if name != table {
+			fmt.Fprintf(&b, "\n[colors.%s]\n", name)
+			table = name
+		}
+		fmt.Fprintf(&b, "%s = %q\n", key, s.Value)
+	}
+	if err := os.WriteFile(a.path, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	added, err := a.ensureImport()
+	a.imported = a.imported || added
+	return err
+}
+
+// Revert restores the generated file as it was before the first Apply. The
+// file and its import are removed if Apply created them, leaving
+// alacritty.toml as it was.
+func (a *Inline) Revert() error {
+	if !a.applied {
+		return nil
+	}
+	if a.imported {
+		if _, err := a.dropImport(); err != nil {
+			return err
+		}
+		a.imported = false
+	}
+	if a.previous == nil {
+		if err := os.Remove(a.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(a.path, a.previous, 0644)
+}
+
+// Reset drops the generated file from the imports of alacritty.toml and
+// removes it, so that the imported theme shows again.
+func (a *Inline) Reset() error {
+	if _, err := a.dropImport(); err != nil {
+		return err
+	}
+	if err := os.Remove(a.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (a *Inline) Capabilities() Capabilities {
+	return Capabilities{Persistent: true, AllWindows: true}
+}
+
+// ensureImport adds the generated file to the end of the import list of
+// alacritty.toml, unless it is there already, and tells whether it added it.
+func (a *Inline) ensureImport() (bool, error) {
+	configPath := a.config.Paths.AlacrittyConfigPath
+	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return false, fmt.Errorf("%w: %s", it.ErrConfigNotFound, configPath)
+	}
+	if err != nil {
+		return false, err
+	}
+	quoted := "\"" + a.path + "\""
+	if strings.Contains(string(content), quoted) {
+		return false, nil
+	}
+
+	var modified string
+	if loc := importListRe.FindSubmatchIndex(content); loc != nil {
+		entries := string(content[loc[2]:loc[3]])
+		kept := strings.TrimRight(entries, " \t\r\n,")
+		switch {
+		case !strings.Contains(entries, "\n"):
+			// A list on one line stays on one line
+			if kept != "" {
+				kept += ", "
+			}
+			kept += quoted
+		case kept != "":
+			kept += ",\n" + quoted + "\n"
+		default:
+			kept = quoted + "\n"
+		}
+		modified = string(content[:loc[2]]) + kept + string(content[loc[3]:])
+	} else {
+		modified = "import = [\n" + quoted + "\n]\n\n" + string(content)
+	}
+	return true, os.WriteFile(configPath, []byte(modified), 0644)
+}
+
+// dropImport removes the generated file from the import list of
+// alacritty.toml and tells whether it was there. A list left empty is
+// removed. A missing alacritty.toml imports nothing.
+func (a *Inline) dropImport() (bool, error) {
+	configPath := a.config.Paths.AlacrittyConfigPath
+	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	loc := importListRe.FindSubmatchIndex(content)
+	if loc == nil {
+		return false, nil
+	}
+	entryRe := regexp.MustCompile(`\s*["']` + regexp.QuoteMeta(a.path) + `["']\s*,?`)
+	entries := content[loc[2]:loc[3]]
+	if !entryRe.Match(entries) {
+		return false, nil
+	}
+	kept := strings.TrimLeft(string(entryRe.ReplaceAll(entries, nil)), " \t")
+	// The comma before the entry stays behind if it was the last one
+	kept = strings.TrimRight(kept, " \t\r\n,")
+	if strings.TrimSpace(kept) == "" {
+		// Remove the list along with the blank line ensureImport put after it
+		rest := strings.TrimPrefix(string(content[loc[1]:]), "\n")
+		if loc[0] == 0 {
+			rest = strings.TrimPrefix(rest, "\n")
+		}
+		return true, os.WriteFile(configPath, []byte(string(content[:loc[0]])+rest), 0644)
+	}
+	if strings.Contains(string(entries), "\n") {
+		// Keep the closing bracket on its own line
+		kept += "\n"
+	}
+	modified := string(content[:loc[2]]) + kept + string(content[loc[3]:])
+	return true, os.WriteFile(configPath, []byte(modified), 0644)
+}
@@ -0,0 +1,46 @@
+package applier
+
+import (
+	"errors"
+
+	"goalacritty_themes/ipc"
+	it "goalacritty_themes/theme_tools"
+)
+
+// IPC recolors running alacritty windows over alacritty's socket. The
+// colors last until they are reset or the window is closed.
+type IPC struct {
+	// Window is the ID of the window to recolor, or ipc.AllWindows
+	Window int64
+}
+
+// NewIPC returns an IPC applier for the window the program runs in, or for
+// every window outside of alacritty.
+func NewIPC() *IPC {
+	return &IPC{Window: ipc.Window()}
+}
+
+func (a *IPC) Apply(theme it.ThemeData) error {
+	if theme.Palette == nil {
+		return &it.ThemeError{Theme: theme.Name, Err: errors.New("the theme could not be parsed")}
+	}
+	// Instances come and go, so the sockets are looked up every time
+	sockets, err := ipc.Sockets()
+	if err != nil {
+		return err
+	}
+	return ipc.SetOptions(sockets, a.Window, ipc.Options(*theme.Palette))
+}
+
+// Revert restores the colors of the alacritty config in the window.
+func (a *IPC) Revert() error {
+	sockets, err := ipc.Sockets()
+	if err != nil {
+		return err
+	}
+	return ipc.Reset(sockets, a.Window)
+}
+
+func (a *IPC) Capabilities() Capabilities {
+	return Capabilities{AllWindows: a.Window == ipc.AllWindows}
+}
@@ -0,0 +1,41 @@
+package applier
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"goalacritty_themes/osc"
+	it "goalacritty_themes/theme_tools"
+)
+
+// OSC recolors the terminal the program runs in with escape sequences. It
+// works in any terminal, also over SSH, and lasts until the terminal is
+// closed or reset.
+type OSC struct {
+	// Out is the terminal, standard output by default
+	Out io.Writer
+}
+
+// NewOSC returns an OSC applier writing to standard output.
+func NewOSC() *OSC {
+	return &OSC{Out: os.Stdout}
+}
+
+func (a *OSC) Apply(theme it.ThemeData) error {
+	if theme.Palette == nil {
+		return &it.ThemeError{Theme: theme.Name, Err: errors.New("the theme could not be parsed")}
+	}
+	_, err := io.WriteString(a.Out, osc.Wrap(osc.Palette(*theme.Palette)))
+	return err
+}
+
+// Revert restores the colors of the terminal's config.
+func (a *OSC) Revert() error {
+	_, err := io.WriteString(a.Out, osc.Wrap(osc.Reset()))
+	return err
+}
+
+func (a *OSC) Capabilities() Capabilities {
+	return Capabilities{}
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/portal"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "follow-desktop",
+		usage:   "follow-desktop",
+		summary: "apply the [appearance] themes when the desktop switches between dark and light",
+		run:     runFollowDesktop,
+	})
+}
+
+func runFollowDesktop(config cf.Config, args []string) error {
+	if err := newFlagSet("follow-desktop").Parse(args); err != nil {
+		return err
+	}
+	selectors, err := appearanceSelectors(config)
+	if err != nil {
+		return err
+	}
+
+	settings, err := portal.Connect()
+	if err != nil {
+		return err
+	}
+	defer settings.Close()
+	// Subscribe first so that a change while reading the current scheme is
+	// not missed
+	changes, err := settings.Watch()
+	if err != nil {
+		return err
+	}
+	scheme, err := settings.ColorScheme()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for {
+		if sel, ok := selectors[scheme]; ok {
+			if err := applySelected(config, sel); err != nil {
+				logf("could not apply %s for %s: %v", sel, scheme, err)
+			} else {
+				logf("applied %s for %s", sel, scheme)
+			}
+		} else {
+			logf("the desktop has %s, the theme is left alone", scheme)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case s, ok := <-changes:
+			if !ok {
+				return errors.New("lost the connection to the session bus")
+			}
+			scheme = s
+		}
+	}
+}
+
+// appearanceSelectors returns the configured themes by color scheme.
+func appearanceSelectors(config cf.Config) (map[portal.ColorScheme]it.Selector, error) {
+	a := config.Appearance
+	selectors := map[portal.ColorScheme]it.Selector{}
+	for _, c := range []struct {
+		scheme        portal.ColorScheme
+		theme, filter string
+	}{
+		{portal.PreferDark, a.DarkTheme, a.DarkFilter},
+		{portal.PreferLight, a.LightTheme, a.LightFilter},
+	} {
+		if c.theme == "" && c.filter == "" {
+			continue
+		}
+		sel, err := it.NewSelector(c.theme, c.filter)
+		if err != nil {
+			return nil, fmt.Errorf("appearance %s: %w", c.scheme, err)
+		}
+		selectors[c.scheme] = sel
+	}
+	if len(selectors) == 0 {
+		return nil, errors.New("no dark or light themes in the [appearance] config")
+	}
+	return selectors, nil
+}
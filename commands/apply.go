@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"goalacritty_themes/applier"
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/hooks"
+	"goalacritty_themes/ipc"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "apply",
+		usage:   "apply [--backend name] [--window id|--all] <theme>|--reset",
+		summary: "switch to a theme, with the backend of the [apply] config or another one",
+		run:     runApply,
+	})
+}
+
+func runApply(config cf.Config, args []string) error {
+	fs := newFlagSet("apply")
+	backend := backendFlag(fs)
+	window := fs.Int64("window", ipc.AllWindows, "ID of the window the ipc backend recolors, the one it runs in by default")
+	all := fs.Bool("all", false, "recolor every window with the ipc backend")
+	reset := fs.Bool("reset", false, "drop the colors applied by the inline, osc or ipc backend")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	a, err := applier.New(config, *backend)
+	if err != nil {
+		return err
+	}
+	windowSet := false
+	fs.Visit(func(f *flag.Flag) { windowSet = windowSet || f.Name == "window" })
+	if live, ok := a.(*applier.IPC); ok {
+		if windowSet {
+			live.Window = *window
+		}
+		if *all {
+			live.Window = ipc.AllWindows
+		}
+	} else if windowSet || *all {
+		return errors.New("--window and --all need the ipc backend")
+	}
+
+	if *reset {
+		if inline, ok := a.(*applier.Inline); ok && fs.NArg() == 0 {
+			return inline.Reset()
+		}
+		if fs.NArg() != 0 || a.Capabilities().Persistent {
+			return errors.New("usage: apply --backend inline|osc|ipc [--window id|--all] --reset")
+		}
+		return a.Revert()
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: apply [--backend name] [--window id|--all] <theme>")
+	}
+	sel, err := it.NewSelector(fs.Arg(0), "")
+	if err != nil {
+		return err
+	}
+	theme, err := sel.Find(config, "")
+	if err != nil {
+		return err
+	}
+	return applyTheme(config, a, theme)
+}
+
+// backendFlag registers --backend on fs.
+func backendFlag(fs *flag.FlagSet) *string {
+	return fs.String("backend", "", "how to apply the theme: "+strings.Join(applier.Names(), ", ")+"; the [apply] config by default")
+}
+
+// applyTheme applies theme with a, records its use and runs the [[hooks]].
+// Failing hooks are reported on stderr: the theme is switched all the same.
+func applyTheme(config cf.Config, a applier.Applier, theme it.ThemeData) error {
+	// A broken hooks config is reported before anything changes
+	hs, err := hooks.FromConfig(config)
+	if err != nil {
+		return err
+	}
+	if err := a.Apply(theme); err != nil {
+		return err
+	}
+	if err := recordUse(theme.Name); err != nil {
+		return err
+	}
+	if err := hooks.Run(context.Background(), hs, hooks.NewTheme(theme)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return nil
+}
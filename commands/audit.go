@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/palette"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "audit",
+		usage:   "audit [--dark|--light] [--sort score|name] [theme...]",
+		summary: "check the contrast and readability of themes",
+		run:     runAudit,
+	})
+}
+
+func runAudit(config cf.Config, args []string) error {
+	fs := newFlagSet("audit")
+	class := classFlags(fs)
+	sortBy := fs.String("sort", "score", "sort the summary by score or name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	filter, err := class()
+	if err != nil {
+		return err
+	}
+	if *sortBy != "score" && *sortBy != "name" {
+		return fmt.Errorf("cannot sort by %q", *sortBy)
+	}
+
+	themes, err := it.GetThemeDataNames(config)
+	if err != nil {
+		return err
+	}
+
+	// Named themes get the full report
+	if fs.NArg() > 0 {
+		for _, name := range fs.Args() {
+			theme, err := findTheme(themes, name)
+			if err != nil {
+				return err
+			}
+			if theme.Palette == nil {
+				return fmt.Errorf("%s: theme could not be parsed", name)
+			}
+			printAudit(theme.Name, theme.Palette.Audit())
+		}
+		return nil
+	}
+
+	type row struct {
+		name    string
+		audit   palette.AuditResult
+		failing int
+	}
+	var rows []row
+	for _, theme := range it.FilterByClass(themes, filter) {
+		if theme.Palette == nil {
+			continue
+		}
+		audit := theme.Palette.Audit()
+		rows = append(rows, row{theme.Name, audit, len(audit.Failing())})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if *sortBy == "name" {
+			return rows[i].name < rows[j].name
+		}
+		return rows[i].audit.Score > rows[j].audit.Score
+	})
+	fmt.Printf("%-40s %6s  %s\n", "THEME", "SCORE", "FAILING")
+	for _, r := range rows {
+		fmt.Printf("%-40s %6.1f  %d\n", r.name, r.audit.Score, r.failing)
+	}
+	return nil
+}
+
+func printAudit(name string, audit palette.AuditResult) {
+	fmt.Printf("%s (score %.1f)\n", name, audit.Score)
+	fmt.Printf("  %-16s %-9s %-9s %7s %7s\n", "PAIR", "TEXT", "BG", "WCAG", "APCA")
+	for _, r := range audit.Pairs {
+		mark := ""
+		if !r.Pass() {
+			mark = "  FAIL"
+		}
+		fmt.Printf("  %-16s %-9s %-9s %6.2f:1 %7.1f%s\n", r.Name, r.Text.Hex(), r.Background.Hex(), r.WCAG, r.APCA, mark)
+	}
+	fmt.Println("  red/green/yellow distance:")
+	for _, d := range palette.Deficiencies {
+		mark := ""
+		if audit.CVD[d] < palette.MinConfusionDistance {
+			mark = "  FAIL"
+		}
+		fmt.Printf("    %-14s %5.1f%s\n", d, audit.CVD[d], mark)
+	}
+	fmt.Println()
+}
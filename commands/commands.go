@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/palette"
+	it "goalacritty_themes/theme_tools"
+)
+
+// command is a subcommand of the theme changer. Running the program without
+// arguments starts the theme picker instead.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(config cf.Config, args []string) error
+}
+
+var commands = map[string]command{}
+
+func register(c command) {
+	commands[c.name] = c
+}
+
+// ExitStatus is returned by commands which end with the exit status of a
+// program they ran, rather than with an error of their own.
+type ExitStatus int
+
+func (s ExitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+// Run executes the subcommand named by args[0].
+func Run(config cf.Config, args []string) error {
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage()
+		return nil
+	}
+	c, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return c.run(config, args[1:])
+}
+
+func printUsage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Usage: goalacritty_themes [command]")
+	fmt.Println()
+	fmt.Println("Without a command the interactive theme picker is started.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, name := range names {
+		fmt.Printf("  %-30s %s\n", commands[name].usage, commands[name].summary)
+	}
+}
+
+// newFlagSet returns a flag set for the command which reports errors instead of exiting.
+func newFlagSet(c string) *flag.FlagSet {
+	fs := flag.NewFlagSet(c, flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	return fs
+}
+
+// classFlags registers --dark and --light on fs. The returned function
+// reports the selected class after parsing (Unknown if neither was given).
+func classFlags(fs *flag.FlagSet) func() (palette.Class, error) {
+	dark := fs.Bool("dark", false, "only dark themes")
+	light := fs.Bool("light", false, "only light themes")
+	return func() (palette.Class, error) {
+		switch {
+		case *dark && *light:
+			return palette.Unknown, errors.New("--dark and --light are mutually exclusive")
+		case *dark:
+			return palette.Dark, nil
+		case *light:
+			return palette.Light, nil
+		}
+		return palette.Unknown, nil
+	}
+}
+
+// findTheme looks a theme up by name.
+func findTheme(themes []it.ThemeData, name string) (it.ThemeData, error) {
+	for _, theme := range themes {
+		if theme.Name == name {
+			return theme, nil
+		}
+	}
+	return it.ThemeData{}, &it.ThemeError{Theme: name, Err: it.ErrThemeMissing}
+}
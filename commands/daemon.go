@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"goalacritty_themes/applier"
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/ipc"
+	"goalacritty_themes/scheduler"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "daemon",
+		usage:   "daemon",
+		summary: "keep applying the themes of the [[schedule]], [solar] and [rotation] config",
+		run:     runDaemon,
+	})
+}
+
+func runDaemon(config cf.Config, args []string) error {
+	if err := newFlagSet("daemon").Parse(args); err != nil {
+		return err
+	}
+	rules, err := scheduler.FromConfig(config)
+	if err != nil {
+		return err
+	}
+	rotation, err := scheduler.RotationFromConfig(config)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 && rotation == nil {
+		return errors.New("no [[schedule]] rules, [solar] themes or [rotation] in the config")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	d := &scheduler.Daemon{
+		Clock:    scheduler.SystemClock{},
+		Rules:    rules,
+		Apply:    func(r scheduler.Rule) error { return applySelected(config, r.Selector) },
+		Rotation: rotation,
+		Rotate: func(slot time.Time) (string, error) {
+			return rotate(config, rotation, slot, false)
+		},
+		Logf: logf,
+	}
+	if err := d.Run(ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// logf prints a message of a long running command with the time.
+func logf(format string, a ...any) {
+	fmt.Printf("%s %s\n", time.Now().Format(time.DateTime), fmt.Sprintf(format, a...))
+}
+
+// applySelected switches to the theme chosen by s, reading the themes again
+// so that themes installed since a long running command started are found.
+func applySelected(config cf.Config, s it.Selector) error {
+	themes, err := it.GetThemeDataNames(config)
+	if err != nil {
+		return err
+	}
+	current, err := it.GetCurrentTheme(config)
+	if err != nil {
+		current = &it.ThemeData{}
+	}
+	theme, err := s.Pick(themes, current.Name)
+	if err != nil {
+		return err
+	}
+	a, err := applier.New(config, "")
+	if err != nil {
+		return err
+	}
+	if live, ok := a.(*applier.IPC); ok {
+		// Not only the window the command happens to run in
+		live.Window = ipc.AllWindows
+	}
+	if a.Capabilities().Imported && theme.FullPath == current.FullPath {
+		return nil
+	}
+	return applyTheme(config, a, theme)
+}
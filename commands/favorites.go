@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/state"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "fav",
+		usage:   "fav add|rm|ls [theme...]",
+		summary: "manage favorite themes",
+		run:     runFav,
+	})
+	register(command{
+		name:    "tag",
+		usage:   "tag add|rm|ls [theme] [tag...]",
+		summary: "tag themes, or list the tags",
+		run:     runTag,
+	})
+	register(command{
+		name:    "recent",
+		usage:   "recent",
+		summary: "list the recently used themes",
+		run:     runRecent,
+	})
+}
+
+// checkThemes makes sure that every name is an installed theme.
+func checkThemes(config cf.Config, names []string) error {
+	themes, err := it.GetThemeDataNames(config)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := findTheme(themes, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runFav(config cf.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: fav add|rm|ls [theme...]")
+	}
+	store, err := state.Load()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "ls":
+		for _, theme := range store.Favorites {
+			fmt.Println(theme)
+		}
+		return nil
+	case "add":
+		if err := checkThemes(config, args[1:]); err != nil {
+			return err
+		}
+		for _, theme := range args[1:] {
+			store.AddFavorite(theme)
+		}
+	case "rm":
+		for _, theme := range args[1:] {
+			if !store.RemoveFavorite(theme) {
+				return fmt.Errorf("%s is not a favorite", theme)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown fav command %q", args[0])
+	}
+	return store.Save()
+}
+
+func runTag(config cf.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: tag add|rm|ls [theme] [tag...]")
+	}
+	store, err := state.Load()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "ls":
+		if len(args) > 1 {
+			fmt.Println(strings.Join(store.TagsOf(args[1]), " "))
+			return nil
+		}
+		// Without a theme, list every tag with its themes
+		byTag := map[string]bool{}
+		for _, tags := range store.Tags {
+			for _, tag := range tags {
+				byTag[tag] = true
+			}
+		}
+		var tags []string
+		for tag := range byTag {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fmt.Printf("%-20s %s\n", tag, strings.Join(store.Tagged(tag), " "))
+		}
+		return nil
+	case "add", "rm":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: tag %s <theme> <tag...>", args[0])
+		}
+		if args[0] == "rm" {
+			store.RemoveTags(args[1], args[2:]...)
+			break
+		}
+		if err := checkThemes(config, args[1:2]); err != nil {
+			return err
+		}
+		store.AddTags(args[1], args[2:]...)
+	default:
+		return fmt.Errorf("unknown tag command %q", args[0])
+	}
+	return store.Save()
+}
+
+func runRecent(_ cf.Config, _ []string) error {
+	store, err := state.Load()
+	if err != nil {
+		return err
+	}
+	for _, e := range store.Recent {
+		fmt.Printf("%-40s %s\n", e.Theme, e.UsedAt.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+// recordUse adds theme to the recently used themes.
+func recordUse(theme string) error {
+	store, err := state.Load()
+	if err != nil {
+		return err
+	}
+	store.Touch(theme, time.Now())
+	return store.Save()
+}
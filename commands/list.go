@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"goalacritty_themes/applier"
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "list",
+		usage:   "list [--dark|--light]",
+		summary: "list the installed themes with their dark/light class",
+		run:     runList,
+	})
+	register(command{
+		name:    "random",
+		usage:   "random [--dark|--light] [--apply [--backend name]]",
+		summary: "print (or apply) a random theme",
+		run:     runRandom,
+	})
+}
+
+func runList(config cf.Config, args []string) error {
+	fs := newFlagSet("list")
+	class := classFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	filter, err := class()
+	if err != nil {
+		return err
+	}
+
+	themes, err := it.GetThemeDataNames(config)
+	if err != nil {
+		return err
+	}
+	for _, theme := range it.FilterByClass(themes, filter) {
+		fmt.Printf("%-40s %s (%.2f)\n", theme.Name, theme.Class, theme.ClassConfidence)
+	}
+	return nil
+}
+
+func runRandom(config cf.Config, args []string) error {
+	fs := newFlagSet("random")
+	class := classFlags(fs)
+	apply := fs.Bool("apply", false, "make the theme the active one")
+	backend := backendFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	filter, err := class()
+	if err != nil {
+		return err
+	}
+
+	themes, err := it.GetThemeDataNames(config)
+	if err != nil {
+		return err
+	}
+	themes = it.FilterByClass(themes, filter)
+	if len(themes) == 0 {
+		return errors.New("no theme matches")
+	}
+	theme := themes[rand.IntN(len(themes))]
+	if *apply {
+		a, err := applier.New(config, *backend)
+		if err != nil {
+			return err
+		}
+		if err := applyTheme(config, a, theme); err != nil {
+			return err
+		}
+	}
+	fmt.Println(theme.Name)
+	return nil
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/osc"
+	"goalacritty_themes/project"
+)
+
+func init() {
+	register(command{
+		name:    "hook",
+		usage:   "hook bash|zsh|fish",
+		summary: "print the shell code recoloring the terminal by " + project.MarkerFile + " files",
+		run:     runHook,
+	})
+	register(command{
+		name:    "apply-for-dir",
+		usage:   "apply-for-dir <path>",
+		summary: "recolor this terminal with the theme of the nearest " + project.MarkerFile,
+		run:     runApplyForDir,
+	})
+}
+
+// shellHooks are the shell snippets printed by the hook command. They run
+// apply-for-dir whenever the working directory changes; %[1]s is replaced
+// with the command line running it.
+var shellHooks = map[string]string{
+	"bash": `_goalacritty_themes_dir() {
+  [ "$PWD" = "${_goalacritty_themes_pwd-}" ] && return
+  _goalacritty_themes_pwd=$PWD
+  %[1]s "$PWD"
+}
+case ";${PROMPT_COMMAND-};" in
+  *";_goalacritty_themes_dir;"*) ;;
+  *) PROMPT_COMMAND="_goalacritty_themes_dir${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+`,
+	"zsh": `_goalacritty_themes_dir() {
+  %[1]s "$PWD"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _goalacritty_themes_dir
+_goalacritty_themes_dir
+`,
+	"fish": `function _goalacritty_themes_dir --on-variable PWD
+    %[1]s "$PWD"
+end
+_goalacritty_themes_dir
+`,
+}
+
+func runHook(config cf.Config, args []string) error {
+	if len(args) != 1 || shellHooks[args[0]] == "" {
+		return errors.New("usage: hook bash|zsh|fish")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if strings.Contains(exe, "go-build") {
+		return errors.New("go run builds a temporary binary, install the tool with go build first")
+	}
+	// The hook runs in every directory, so it needs the config's full path
+	configPath, err := filepath.Abs(cf.Path())
+	if err != nil {
+		return err
+	}
+	quote := shellQuote
+	if args[0] == "fish" {
+		quote = fishQuote
+	}
+	line := fmt.Sprintf("%s=%s %s apply-for-dir", cf.PathEnv, quote(configPath), quote(exe))
+	fmt.Printf(shellHooks[args[0]], line)
+	return nil
+}
+
+// shellQuote quotes s for bash and zsh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish, where backslashes escape in single quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// runApplyForDir recolors the terminal it runs in, leaving the alacritty
+// config alone.
+func runApplyForDir(config cf.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: apply-for-dir <path>")
+	}
+	seq, err := dirColors(config, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(osc.Wrap(seq))
+	return nil
+}
+
+// dirColors returns the sequences recoloring a terminal for dir: the theme
+// of its marker file or, outside of projects, the colors of the config.
+func dirColors(config cf.Config, dir string) (string, error) {
+	marker, ok, err := project.Find(dir)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return osc.Reset(), nil
+	}
+	theme, err := marker.Find(config, "")
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", marker.Path, err)
+	}
+	if theme.Palette == nil {
+		return "", fmt.Errorf("%s: the theme %s could not be parsed", marker.Path, theme.Name)
+	}
+	return osc.Palette(*theme.Palette), nil
+}
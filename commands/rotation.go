@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/scheduler"
+	"goalacritty_themes/state"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "rotation",
+		usage:   "rotation status|skip",
+		summary: "show the theme rotation, or move on to its next theme",
+		run:     runRotation,
+	})
+}
+
+func runRotation(config cf.Config, args []string) error {
+	if len(args) != 1 || args[0] != "status" && args[0] != "skip" {
+		return errors.New("usage: rotation status|skip")
+	}
+	rotation, err := scheduler.RotationFromConfig(config)
+	if err != nil {
+		return err
+	}
+	if rotation == nil {
+		return errors.New("no [rotation] in the config")
+	}
+	rules, err := scheduler.FromConfig(config)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	i, ruled := scheduler.Active(rules, now)
+
+	if args[0] == "skip" {
+		if ruled {
+			return fmt.Errorf("the schedule rule %s is in effect", rules[i])
+		}
+		theme, err := rotate(config, rotation, rotation.Slot(now), true)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Applied", theme)
+		return nil
+	}
+
+	store, err := state.Load()
+	if err != nil {
+		return err
+	}
+	themes, err := it.GetThemeDataNames(config)
+	if err != nil {
+		return err
+	}
+	playlist := rotation.Playlist(themes, store)
+	fmt.Printf("%-10s %s (%d themes)\n", "rotation", rotation, len(playlist))
+	current := store.Rotation
+	if current.Theme != "" && current.Slot.Equal(rotation.Slot(now)) {
+		fmt.Printf("%-10s %s, since %s\n", "current", current.Theme, current.Slot.Local().Format("Mon 15:04"))
+	} else {
+		fmt.Printf("%-10s none, the daemon chooses one\n", "current")
+	}
+	if ruled {
+		fmt.Printf("%-10s paused by the schedule rule %s\n", "", rules[i])
+	}
+	next := rotation.NextSlot(now)
+	theme, err := rotation.Choose(playlist, current.Theme, store.RecentThemes(), next)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-10s %s at %s\n", "next", theme, next.Format("Mon 15:04"))
+	return nil
+}
+
+// rotate applies the rotation's theme for the slot starting at slot and
+// returns its name. The theme chosen for the slot earlier, e.g. by another
+// run of the daemon, is kept unless skip asks for another one.
+func rotate(config cf.Config, rotation *scheduler.Rotation, slot time.Time, skip bool) (string, error) {
+	store, err := state.Load()
+	if err != nil {
+		return "", err
+	}
+	theme := store.Rotation.Theme
+	if skip || theme == "" || !store.Rotation.Slot.Equal(slot) {
+		themes, err := it.GetThemeDataNames(config)
+		if err != nil {
+			return "", err
+		}
+		theme, err = rotation.Choose(rotation.Playlist(themes, store), store.Rotation.Theme, store.RecentThemes(), slot)
+		if err != nil {
+			return "", err
+		}
+		// Saved first, applySelected updates the store on its own
+		store.Rotation = state.RotationState{Theme: theme, Slot: slot}
+		if err := store.Save(); err != nil {
+			return "", err
+		}
+	}
+	sel, err := it.NewSelector(theme, "")
+	if err != nil {
+		return "", err
+	}
+	return theme, applySelected(config, sel)
+}
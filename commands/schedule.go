@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/scheduler"
+)
+
+func init() {
+	register(command{
+		name:    "schedule",
+		usage:   "schedule preview [--days n]",
+		summary: "print when the daemon will switch themes",
+		run:     runSchedule,
+	})
+}
+
+func runSchedule(config cf.Config, args []string) error {
+	if len(args) == 0 || args[0] != "preview" {
+		return errors.New("usage: schedule preview [--days n]")
+	}
+	fs := newFlagSet("schedule preview")
+	days := fs.Int("days", 7, "number of days to preview")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	rules, err := scheduler.FromConfig(config)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if i, ok := scheduler.Active(rules, now); ok {
+		fmt.Printf("%-22s %s\n", "now", rules[i])
+	}
+	for _, s := range scheduler.Switches(rules, now, now.AddDate(0, 0, *days)) {
+		rule := "no rule, the theme is left alone"
+		if s.Rule >= 0 {
+			rule = rules[s.Rule].String()
+		}
+		fmt.Printf("%-22s %s\n", s.At.Format("Mon 2006-01-02 15:04"), rule)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+func init() {
+	register(command{
+		name:    "similar",
+		usage:   "similar [-n count] <theme>",
+		summary: "list the themes whose palettes are closest to a theme",
+		run:     runSimilar,
+	})
+}
+
+func runSimilar(config cf.Config, args []string) error {
+	fs := newFlagSet("similar")
+	count := fs.Int("n", 10, "number of themes to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: similar [-n count] <theme>")
+	}
+
+	themes, err := it.GetThemeDataNames(config)
+	if err != nil {
+		return err
+	}
+	matches, err := it.NewSimilarityIndex(themes).Similar(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	for i, m := range matches {
+		if i == *count {
+			break
+		}
+		fmt.Printf("%-40s %6.2f\n", m.Theme.Name, m.Distance)
+	}
+	return nil
+}
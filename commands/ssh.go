@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/osc"
+	"goalacritty_themes/sshhost"
+)
+
+func init() {
+	register(command{
+		name:    "ssh-wrap",
+		usage:   "ssh-wrap <ssh arguments>",
+		summary: "run ssh with this terminal recolored by the [[ssh_hosts]] config",
+		run:     runSSHWrap,
+	})
+}
+
+// runSSHWrap runs ssh with args. If the destination matches a rule, the
+// terminal shows the rule's theme until ssh exits, then the colors it had
+// before: those of the working directory's theme or of the config.
+func runSSHWrap(config cf.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: ssh-wrap <ssh arguments>")
+	}
+	rules, err := sshhost.ParseRules(config.SSHHosts)
+	if err != nil {
+		return err
+	}
+	user, host, ok := sshhost.Destination(args)
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	rule, matched := sshhost.Match(rules, user, host)
+	if !ok || !matched {
+		return runSSH(args)
+	}
+
+	// Connecting matters more than the colors, so problems with the theme
+	// are only reported
+	theme, err := rule.Find(config, "")
+	if err == nil && theme.Palette == nil {
+		err = fmt.Errorf("the theme %s could not be parsed", theme.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ssh-wrap: not recoloring for %s: %v\n", host, err)
+		return runSSH(args)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "/"
+	}
+	restore, err := dirColors(config, cwd)
+	if err != nil {
+		restore = osc.Reset()
+	}
+
+	// The terminal itself is recolored, so that the output of ssh stays
+	// clean when it is redirected, as in ssh host tar c | tar x. Without a
+	// terminal there is nothing to recolor.
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return runSSH(args)
+	}
+	defer tty.Close()
+	fmt.Fprint(tty, osc.Wrap(osc.Palette(*theme.Palette)))
+	defer fmt.Fprint(tty, osc.Wrap(restore))
+	return runSSH(args)
+}
+
+// runSSH runs ssh in the terminal and returns its exit status. Signals
+// ending the wrapper are passed on to ssh, so that the wrapper lives until
+// ssh is gone and can restore the colors.
+func runSSH(args []string) error {
+	cmd := exec.Command("ssh", args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	// The terminal sends SIGINT and SIGQUIT to ssh as well, they are only
+	// kept from ending the wrapper
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case s := <-signals:
+				if s == syscall.SIGTERM || s == syscall.SIGHUP {
+					cmd.Process.Signal(s)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return ExitStatus(128 + int(status.Signal()))
+	}
+	return ExitStatus(exitErr.ExitCode())
+}
@@ -17,6 +17,114 @@ type Config struct {
 	Repos struct {
 		ThemeURL string `toml:"theme_url"`
 	} `toml:"repos"`
+	// Sources maps names to additional directories of theme files, next to
+	// the themes of the cloned repository
+	Sources map[string]string `toml:"sources"`
+	Preview struct {
+		// ScenesDirectory holds extra sample scenes, one file with ANSI
+		// escapes per scene
+		ScenesDirectory string `toml:"scenes_directory"`
+	} `toml:"preview"`
+	UI struct {
+		// Mouse enables clicking and scrolling in the picker, at the cost
+		// of the terminal's own text selection
+		Mouse bool `toml:"mouse"`
+	} `toml:"ui"`
+	// Schedule lists the themes to apply at times of the day, used by the
+	// daemon command
+	Schedule []ScheduleRule `toml:"schedule"`
+	// Solar switches between a day and a night theme at sunrise and sunset,
+	// computed for the location. Schedule rules can use the sun events too.
+	Solar struct {
+		Latitude  float64 `toml:"latitude"`
+		Longitude float64 `toml:"longitude"`
+		// Twilight switches at civil dawn and dusk instead
+		Twilight    bool   `toml:"twilight"`
+		DayTheme    string `toml:"day_theme"`
+		DayFilter   string `toml:"day_filter"`
+		NightTheme  string `toml:"night_theme"`
+		NightFilter string `toml:"night_filter"`
+	} `toml:"solar"`
+	// Rotation cycles through a playlist of themes while no schedule rule is
+	// in effect, used by the daemon and the rotation command
+	Rotation struct {
+		// The playlist is the favorites, the themes with a tag or the
+		// themes matching a filter query
+		Favorites bool   `toml:"favorites"`
+		Tag       string `toml:"tag"`
+		Filter    string `toml:"filter"`
+		// Interval like "2h" moves on to the next theme of the playlist;
+		// without it a theme of the day is chosen
+		Interval string `toml:"interval"`
+		// AvoidRecent is how many of the recently used themes are not
+		// chosen again, 5 if not set; 0 avoids none
+		AvoidRecent *int `toml:"avoid_recent"`
+	} `toml:"rotation"`
+	// Appearance maps the desktop's dark and light preference to themes,
+	// used by the follow-desktop command
+	Appearance struct {
+		DarkTheme   string `toml:"dark_theme"`
+		DarkFilter  string `toml:"dark_filter"`
+		LightTheme  string `toml:"light_theme"`
+		LightFilter string `toml:"light_filter"`
+	} `toml:"appearance"`
+	// Apply chooses how the picker and the commands switch themes, see
+	// the applier package for the backends
+	Apply struct {
+		Backend string `toml:"backend"`
+		// GeneratedFile is where the inline backend writes the colors,
+		// next to alacritty.toml by default
+		GeneratedFile string `toml:"generated_file"`
+	} `toml:"apply"`
+	// SSHHosts recolors the terminal during ssh sessions to matching hosts,
+	// used by the ssh-wrap command
+	SSHHosts []SSHHost `toml:"ssh_hosts"`
+	// Hooks run commands after each switch of the theme, so that other
+	// programs like tmux or an editor can follow it
+	Hooks []Hook `toml:"hooks"`
+	// Keys rebinds actions of the theme picker, e.g. quit = ["q", "esc"]
+	Keys map[string][]string `toml:"keys"`
+}
+
+// ScheduleRule applies a theme between two times of the day ("07:30"), or
+// sun events ("sunset", "dawn+15m"). The
+// theme is either named, or chosen among the themes matching a filter query.
+type ScheduleRule struct {
+	From   string `toml:"from"`
+	To     string `toml:"to"`
+	Theme  string `toml:"theme"`
+	Filter string `toml:"filter"`
+}
+
+// SSHHost applies a theme to the hosts matching a glob pattern, e.g.
+// "*.prod.example.com" or "root@*". The theme is either named, or chosen
+// among the themes matching a filter query.
+type SSHHost struct {
+	Pattern string `toml:"pattern"`
+	Theme   string `toml:"theme"`
+	Filter  string `toml:"filter"`
+}
+
+// Hook is a command run after the theme was switched. Command holds the
+// program and its arguments, which can be templates like "{{.Background}}".
+// Timeout like "5s" bounds how long it may run.
+type Hook struct {
+	Name    string   `toml:"name"`
+	Command []string `toml:"command"`
+	Timeout string   `toml:"timeout"`
+}
+
+// PathEnv names the environment variable which overrides the config's path.
+// Shell hooks set it, as they run the tool in other directories.
+const PathEnv = "GOALACRITTY_THEMES_CONFIG"
+
+// Path returns the path of the config file: config.toml in the working
+// directory, unless PathEnv is set.
+func Path() string {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path
+	}
+	return "config.toml"
 }
 
 // LoadConfig reads a TOML file and returns a Config instance.
@@ -33,6 +141,16 @@ func LoadConfig(path string) (*Config, error) {
 	}
 	config.Paths.AlacrittyConfigPath = expandHome(config.Paths.AlacrittyConfigPath)
 	config.Paths.ThemesDirectory = expandHome(config.Paths.ThemesDirectory)
+	for name, dir := range config.Sources {
+		config.Sources[name] = expandHome(dir)
+	}
+	config.Preview.ScenesDirectory = expandHome(config.Preview.ScenesDirectory)
+	config.Apply.GeneratedFile = expandHome(config.Apply.GeneratedFile)
+	for i, hook := range config.Hooks {
+		if len(hook.Command) > 0 {
+			config.Hooks[i].Command[0] = expandHome(hook.Command[0])
+		}
+	}
 
 	return config, nil
 }
@@ -54,6 +54,37 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "https://github.com/some/repo", config.Repos.ThemeURL, "ThemeURL should match")
 }
 
+// TestLoadConfigSources tests that additional theme sources are read and expanded.
+func TestLoadConfigSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte(mockConfig+"\n[sources]\nwork = \"~/work/themes\"\n"), 0644)
+	if err != nil {
+		t.Fatalf("Error writing config: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	usr, _ := user.Current()
+	assert.Equal(t, map[string]string{"work": filepath.Join(usr.HomeDir, "work/themes")}, config.Sources)
+}
+
+// TestLoadConfigKeys tests that rebound keys of the picker are read.
+func TestLoadConfigKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte(mockConfig+"\n[keys]\nfavorite = [\"*\", \"f\"]\n"), 0644)
+	if err != nil {
+		t.Fatalf("Error writing config: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	assert.Equal(t, map[string][]string{"favorite": {"*", "f"}}, config.Keys)
+}
+
 // TestLoadConfigFileNotFound tests LoadConfig when the file doesn't exist.
 func TestLoadConfigFileNotFound(t *testing.T) {
 	_, err := LoadConfig("non_existent_file.toml")
@@ -62,6 +93,14 @@ func TestLoadConfigFileNotFound(t *testing.T) {
 	}
 }
 
+// TestPath tests that the environment overrides the config's path
+func TestPath(t *testing.T) {
+	t.Setenv(PathEnv, "")
+	assert.Equal(t, "config.toml", Path())
+	t.Setenv(PathEnv, "/etc/goalacritty/config.toml")
+	assert.Equal(t, "/etc/goalacritty/config.toml", Path())
+}
+
 // TestExpandHome tests the expandHome function.
 func TestExpandHome(t *testing.T) {
 	usr, _ := user.Current()
@@ -0,0 +1,179 @@
+// Package hooks runs the commands of the [[hooks]] config after the theme
+// was switched, so that programs like tmux, an editor or a pager can follow
+// alacritty's colors.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/palette"
+	it "goalacritty_themes/theme_tools"
+)
+
+// DefaultTimeout is how long a hook may run if its config sets no timeout.
+const DefaultTimeout = 10 * time.Second
+
+// outputLimit is how much of the output of a failed hook is reported.
+const outputLimit = 500
+
+// Hook is a command run after the theme was switched.
+type Hook struct {
+	Name string
+	// Command holds the templates of the program and its arguments
+	Command []*template.Template
+	Timeout time.Duration
+}
+
+// FromConfig checks and parses the hooks of the [[hooks]] config section.
+func FromConfig(config cf.Config) ([]Hook, error) {
+	hooks := make([]Hook, len(config.Hooks))
+	for i, c := range config.Hooks {
+		var err error
+		if hooks[i], err = parseHook(c); err != nil {
+			return nil, fmt.Errorf("hook %d: %w", i+1, err)
+		}
+	}
+	return hooks, nil
+}
+
+func parseHook(c cf.Hook) (Hook, error) {
+	if len(c.Command) == 0 || c.Command[0] == "" {
+		return Hook{}, errors.New("no command")
+	}
+	h := Hook{Name: c.Name, Timeout: DefaultTimeout}
+	if h.Name == "" {
+		h.Name = c.Command[0]
+	}
+	if c.Timeout != "" {
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil || d <= 0 {
+			return Hook{}, fmt.Errorf("invalid timeout %q", c.Timeout)
+		}
+		h.Timeout = d
+	}
+	for _, arg := range c.Command {
+		t, err := template.New(h.Name).Parse(arg)
+		if err == nil {
+			// Catch unknown fields now rather than after every switch
+			err = t.Execute(&bytes.Buffer{}, Theme{})
+		}
+		if err != nil {
+			return Hook{}, fmt.Errorf("invalid argument %q: %w", arg, err)
+		}
+		h.Command = append(h.Command, t)
+	}
+	return h, nil
+}
+
+// Theme describes the theme switched to, to the templates of the hooks and
+// in their environment. Colors are in "#rrggbb" notation and empty if the
+// theme file could not be parsed.
+type Theme struct {
+	Name  string
+	Path  string
+	Class string // dark, light or unknown
+	// Background and Foreground are the primary colors
+	Background string
+	Foreground string
+	// Colors are the 16 terminal colors, normal then bright
+	Colors [16]string
+}
+
+// NewTheme describes theme, reading its palette if it was not yet.
+func NewTheme(theme it.ThemeData) Theme {
+	t := Theme{Name: theme.Name, Path: theme.FullPath, Class: palette.Unknown.String()}
+	p := theme.Palette
+	if p == nil {
+		var err error
+		if p, err = palette.Load(theme.FullPath); err != nil {
+			return t
+		}
+	}
+	class, _ := p.Classify()
+	t.Class = class.String()
+	t.Background, t.Foreground = p.Background.Hex(), p.Foreground.Hex()
+	for i := range t.Colors {
+		t.Colors[i] = p.ANSI(i).Hex()
+	}
+	return t
+}
+
+// Env returns the environment variables describing the theme:
+// GOALACRITTY_THEME, GOALACRITTY_PATH, GOALACRITTY_CLASS, GOALACRITTY_BG,
+// GOALACRITTY_FG and GOALACRITTY_COLOR0 to GOALACRITTY_COLOR15.
+func (t Theme) Env() []string {
+	env := []string{
+		"GOALACRITTY_THEME=" + t.Name,
+		"GOALACRITTY_PATH=" + t.Path,
+		"GOALACRITTY_CLASS=" + t.Class,
+		"GOALACRITTY_BG=" + t.Background,
+		"GOALACRITTY_FG=" + t.Foreground,
+	}
+	for i, c := range t.Colors {
+		env = append(env, fmt.Sprintf("GOALACRITTY_COLOR%d=%s", i, c))
+	}
+	return env
+}
+
+// Run runs the hook for theme and waits for it, killing it after its
+// timeout. The output of a failed hook is part of the error.
+func (h Hook) Run(ctx context.Context, theme Theme) error {
+	args := make([]string, len(h.Command))
+	for i, t := range h.Command {
+		var b strings.Builder
+		if err := t.Execute(&b, theme); err != nil {
+			return fmt.Errorf("hook %s: %w", h.Name, err)
+		}
+		args[i] = b.String()
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), theme.Env()...)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	// Do not wait for children of the hook keeping its output open
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("hook %s timed out after %s", h.Name, h.Timeout)
+	}
+	output := strings.TrimSpace(out.String())
+	if len(output) > outputLimit {
+		cut := len(output) - outputLimit
+		// Do not start in the middle of a character
+		for cut < len(output) && !utf8.RuneStart(output[cut]) {
+			cut++
+		}
+		output = "..." + output[cut:]
+	}
+	if output != "" {
+		return fmt.Errorf("hook %s failed: %w: %s", h.Name, err, output)
+	}
+	return fmt.Errorf("hook %s failed: %w", h.Name, err)
+}
+
+// Run runs the hooks for theme one after the other. A failing hook does not
+// stop the following ones; the failures are returned together.
+func Run(ctx context.Context, hooks []Hook, theme Theme) error {
+	var errs []error
+	for _, h := range hooks {
+		if err := h.Run(ctx, theme); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
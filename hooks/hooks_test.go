@@ -0,0 +1,154 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+const themeFile = `[colors.primary]
+background = '#1e1e2e'
+foreground = '#cdd6f4'
+[colors.normal]
+black = '#000000'
+red = '#ff0000'
+green = '#00ff00'
+yellow = '#ffff00'
+blue = '#0000ff'
+magenta = '#ff00ff'
+cyan = '#00ffff'
+white = '#ffffff'
+[colors.bright]
+black = '#101010'
+red = '#ff1010'
+green = '#10ff10'
+yellow = '#ffff10'
+blue = '#1010ff'
+magenta = '#ff10ff'
+cyan = '#10ffff'
+white = '#fefefe'
+`
+
+// theme writes themeFile and returns its description for the hooks.
+func theme(t *testing.T) Theme {
+	path := filepath.Join(t.TempDir(), "mocha.toml")
+	require.NoError(t, os.WriteFile(path, []byte(themeFile), 0644))
+	return NewTheme(it.ThemeData{Name: "mocha", FullPath: path})
+}
+
+// parse returns the hooks of a config holding hooks.
+func parse(t *testing.T, hooks ...cf.Hook) []Hook {
+	parsed, err := FromConfig(cf.Config{Hooks: hooks})
+	require.NoError(t, err)
+	return parsed
+}
+
+// TestFromConfig tests the defaults of hooks and that invalid ones are
+// reported with their position.
+func TestFromConfig(t *testing.T) {
+	hooks := parse(t,
+		cf.Hook{Command: []string{"tmux", "source-file", "{{.Path}}"}},
+		cf.Hook{Name: "bat", Command: []string{"true"}, Timeout: "2s"},
+	)
+	require.Len(t, hooks, 2)
+	assert.Equal(t, "tmux", hooks[0].Name)
+	assert.Equal(t, DefaultTimeout, hooks[0].Timeout)
+	assert.Len(t, hooks[0].Command, 3)
+	assert.Equal(t, "bat", hooks[1].Name)
+	assert.Equal(t, 2*time.Second, hooks[1].Timeout)
+
+	for _, c := range []struct {
+		hook cf.Hook
+		err  string
+	}{
+		{cf.Hook{}, "hook 1: no command"},
+		{cf.Hook{Command: []string{"true"}, Timeout: "soon"}, `hook 1: invalid timeout "soon"`},
+		{cf.Hook{Command: []string{"true"}, Timeout: "-1s"}, `hook 1: invalid timeout "-1s"`},
+		{cf.Hook{Command: []string{"echo", "{{.Background"}}, `hook 1: invalid argument "{{.Background"`},
+		{cf.Hook{Command: []string{"echo", "{{.Bg}}"}}, `hook 1: invalid argument "{{.Bg}}"`},
+	} {
+		_, err := FromConfig(cf.Config{Hooks: []cf.Hook{c.hook}})
+		if assert.Error(t, err, c.hook) {
+			assert.True(t, strings.HasPrefix(err.Error(), c.err), err.Error())
+		}
+	}
+}
+
+// TestNewTheme tests the description of a theme and its environment.
+func TestNewTheme(t *testing.T) {
+	th := theme(t)
+	assert.Equal(t, "mocha", th.Name)
+	assert.Equal(t, "dark", th.Class)
+	assert.Equal(t, "#1e1e2e", th.Background)
+	assert.Equal(t, "#cdd6f4", th.Foreground)
+	assert.Equal(t, "#ff0000", th.Colors[1])
+	assert.Equal(t, "#fefefe", th.Colors[15])
+
+	env := th.Env()
+	assert.Len(t, env, 21)
+	assert.Contains(t, env, "GOALACRITTY_THEME=mocha")
+	assert.Contains(t, env, "GOALACRITTY_CLASS=dark")
+	assert.Contains(t, env, "GOALACRITTY_BG=#1e1e2e")
+	assert.Contains(t, env, "GOALACRITTY_COLOR1=#ff0000")
+	assert.Contains(t, env, "GOALACRITTY_COLOR15=#fefefe")
+
+	// Without a palette the colors are left empty
+	missing := NewTheme(it.ThemeData{Name: "gone", FullPath: filepath.Join(t.TempDir(), "gone.toml")})
+	assert.Equal(t, "unknown", missing.Class)
+	assert.Empty(t, missing.Background)
+}
+
+// TestRun tests that a hook gets the theme in its templated arguments and
+// its environment.
+func TestRun(t *testing.T) {
+	th := theme(t)
+	out := filepath.Join(t.TempDir(), "out")
+	hooks := parse(t,
+		cf.Hook{Command: []string{"sh", "-c", `echo "$1 $GOALACRITTY_THEME $GOALACRITTY_BG $GOALACRITTY_COLOR2" > ` + out, "sh", "{{.Class}}"}},
+	)
+	require.NoError(t, Run(context.Background(), hooks, th))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "dark mocha #1e1e2e #00ff00\n", string(data))
+}
+
+// TestRunFailures tests that failing and hanging hooks are reported without
+// stopping the following ones.
+func TestRunFailures(t *testing.T) {
+	th := theme(t)
+	out := filepath.Join(t.TempDir(), "out")
+	hooks := parse(t,
+		cf.Hook{Name: "broken", Command: []string{"sh", "-c", "echo no such session >&2; exit 3"}},
+		cf.Hook{Name: "slow", Command: []string{"sleep", "5"}, Timeout: "100ms"},
+		cf.Hook{Command: []string{"touch", out}},
+	)
+	start := time.Now()
+	err := Run(context.Background(), hooks, th)
+	assert.Less(t, time.Since(start), 3*time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hook broken failed: exit status 3: no such session")
+	assert.Contains(t, err.Error(), "hook slow timed out after 100ms")
+	// The hooks after a failing one still run
+	assert.FileExists(t, out)
+}
+
+// TestRunLongOutput tests that the output of a failed hook is cut at the
+// start of a character.
+func TestRunLongOutput(t *testing.T) {
+	hooks := parse(t,
+		cf.Hook{Name: "verbose", Command: []string{"sh", "-c", "printf 'é%.0s' $(seq 400); echo x; exit 1"}},
+	)
+	err := Run(context.Background(), hooks, theme(t))
+	require.Error(t, err)
+	assert.True(t, utf8.ValidString(err.Error()), err.Error())
+	assert.Contains(t, err.Error(), ": ...é")
+}
@@ -0,0 +1,123 @@
+// Package ipc changes the configuration of running alacritty windows over
+// alacritty's IPC socket, like `alacritty msg config` does. The changes last
+// until they are reset or the window is closed; alacritty.toml is not
+// touched.
+package ipc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"goalacritty_themes/palette"
+)
+
+const (
+	// SocketEnv names the socket of the alacritty instance a shell runs in.
+	SocketEnv = "ALACRITTY_SOCKET"
+	// WindowEnv names the ID of the window a shell runs in.
+	WindowEnv = "ALACRITTY_WINDOW_ID"
+	// AllWindows as window ID addresses every window of an instance.
+	AllWindows = -1
+
+	timeout = 2 * time.Second
+)
+
+// ErrNoSocket is returned when no alacritty instance can be found.
+var ErrNoSocket = errors.New("no alacritty socket found, is alacritty running with ipc_socket enabled?")
+
+// message is the JSON alacritty expects on its socket, one per line.
+type message struct {
+	Config config `json:"Config"`
+}
+
+type config struct {
+	WindowID int64    `json:"window_id"`
+	Options  []string `json:"options"`
+	Reset    bool     `json:"reset"`
+}
+
+// Sockets returns the sockets to send messages to: the one of $ALACRITTY_SOCKET,
+// or else those of every alacritty instance in the runtime directory.
+func Sockets() ([]string, error) {
+	if socket := os.Getenv(SocketEnv); socket != "" {
+		return []string{socket}, nil
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	sockets, err := filepath.Glob(filepath.Join(dir, "Alacritty-*.sock"))
+	if err != nil {
+		return nil, err
+	}
+	if len(sockets) == 0 {
+		return nil, ErrNoSocket
+	}
+	return sockets, nil
+}
+
+// Window returns the ID of the window the program runs in, or AllWindows
+// outside of alacritty.
+func Window() int64 {
+	var id int64
+	if _, err := fmt.Sscan(os.Getenv(WindowEnv), &id); err != nil {
+		return AllWindows
+	}
+	return id
+}
+
+// SetOptions changes the options of window, e.g. colors.primary.background="#000000".
+func SetOptions(sockets []string, window int64, options []string) error {
+	return send(sockets, message{Config: config{WindowID: window, Options: options}})
+}
+
+// Reset drops every option changed over the socket in window.
+func Reset(sockets []string, window int64) error {
+	return send(sockets, message{Config: config{WindowID: window, Options: []string{}, Reset: true}})
+}
+
+// send writes msg to every socket. Sockets without an instance listening
+// are left behind by crashed instances and skipped, as long as one instance
+// got the message.
+func send(sockets []string, msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	var errs []error
+	for _, socket := range sockets {
+		if err := sendTo(socket, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", socket, err))
+		}
+	}
+	if len(errs) == len(sockets) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func sendTo(socket string, data []byte) error {
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	_, err = conn.Write(data)
+	return err
+}
+
+// Options returns the options setting every color of p.
+func Options(p palette.Palette) []string {
+	var options []string
+	for _, s := range p.Settings() {
+		options = append(options, fmt.Sprintf("colors.%s=%q", s.Key, s.Value))
+	}
+	return options
+}
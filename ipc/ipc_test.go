@@ -0,0 +1,119 @@
+package ipc
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goalacritty_themes/palette"
+)
+
+// fakeAlacritty listens on a socket like an alacritty instance and passes on
+// the messages it receives.
+func fakeAlacritty(t *testing.T, socket string) <-chan message {
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	messages := make(chan message, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			line, err := bufio.NewReader(conn).ReadBytes('\n')
+			conn.Close()
+			var msg message
+			if err == nil && json.Unmarshal(line, &msg) == nil {
+				messages <- msg
+			}
+		}
+	}()
+	return messages
+}
+
+func next(t *testing.T, messages <-chan message) message {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a message")
+	}
+	return message{}
+}
+
+// TestSetOptions tests the messages sent to change and reset options
+func TestSetOptions(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "Alacritty-wayland-0-1234.sock")
+	messages := fakeAlacritty(t, socket)
+
+	assert.NoError(t, SetOptions([]string{socket}, 42, []string{`colors.primary.background="#000000"`}))
+	assert.Equal(t, message{Config: config{WindowID: 42, Options: []string{`colors.primary.background="#000000"`}}}, next(t, messages))
+
+	assert.NoError(t, Reset([]string{socket}, AllWindows))
+	assert.Equal(t, message{Config: config{WindowID: AllWindows, Options: []string{}, Reset: true}}, next(t, messages))
+}
+
+// TestWireFormat tests the JSON alacritty expects
+func TestWireFormat(t *testing.T) {
+	data, err := json.Marshal(message{Config: config{WindowID: AllWindows, Options: []string{}, Reset: true}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Config":{"window_id":-1,"options":[],"reset":true}}`, string(data))
+}
+
+// TestSockets tests finding the instances and skipping stale sockets
+func TestSockets(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv(SocketEnv, "")
+
+	_, err := Sockets()
+	assert.ErrorIs(t, err, ErrNoSocket)
+
+	first := fakeAlacritty(t, filepath.Join(dir, "Alacritty-wayland-0-1.sock"))
+	second := fakeAlacritty(t, filepath.Join(dir, "Alacritty-wayland-0-2.sock"))
+	// Left behind by a crashed instance
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Alacritty-wayland-0-3.sock"), nil, 0600))
+	sockets, err := Sockets()
+	assert.NoError(t, err)
+	assert.Len(t, sockets, 3)
+
+	assert.NoError(t, SetOptions(sockets, AllWindows, []string{"window.opacity=0.9"}))
+	assert.Equal(t, []string{"window.opacity=0.9"}, next(t, first).Config.Options)
+	assert.Equal(t, []string{"window.opacity=0.9"}, next(t, second).Config.Options)
+
+	// Only failing everywhere is an error
+	assert.Error(t, SetOptions(sockets[2:], AllWindows, nil))
+
+	t.Setenv(SocketEnv, "/run/user/1000/Alacritty-wayland-0-9.sock")
+	sockets, err = Sockets()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/run/user/1000/Alacritty-wayland-0-9.sock"}, sockets)
+}
+
+// TestWindow tests reading the window of the terminal
+func TestWindow(t *testing.T) {
+	t.Setenv(WindowEnv, "94557896361344")
+	assert.Equal(t, int64(94557896361344), Window())
+	t.Setenv(WindowEnv, "")
+	assert.Equal(t, int64(AllWindows), Window())
+}
+
+// TestOptions tests that every color is set, defaults included
+func TestOptions(t *testing.T) {
+	p, err := palette.Parse([]byte("[colors.primary]\nbackground = \"#1a1b26\"\nforeground = \"#c0caf5\"\n[colors.cursor]\ncursor = \"#ffffff\"\n"))
+	require.NoError(t, err)
+	options := Options(*p)
+	assert.Len(t, options, 22)
+	assert.Contains(t, options, `colors.primary.background="#1a1b26"`)
+	assert.Contains(t, options, `colors.cursor.cursor="#ffffff"`)
+	assert.Contains(t, options, `colors.cursor.text="CellBackground"`)
+	assert.Contains(t, options, `colors.selection.background="CellForeground"`)
+	assert.Contains(t, options, `colors.bright.white="`+p.Bright[7].Hex()+`"`)
+}
@@ -2,8 +2,10 @@ package main
 
 import models "goalacritty_themes/models"
 import (
+	"errors"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
+	"goalacritty_themes/commands"
 	cf "goalacritty_themes/config"
 	it "goalacritty_themes/theme_tools"
 	"os"
@@ -11,11 +13,23 @@ import (
 
 func main() {
 	// Step 1. load config
-	config, err := cf.LoadConfig("config.toml")
+	config, err := cf.LoadConfig(cf.Path())
 	if err != nil {
 		fmt.Println("Error loading config:", err)
 		return
 	}
+	// Subcommands run without the interactive picker
+	if len(os.Args) > 1 {
+		if err := commands.Run(*config, os.Args[1:]); err != nil {
+			var status commands.ExitStatus
+			if errors.As(err, &status) {
+				os.Exit(int(status))
+			}
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
 	// Check if theme repo is in place
 	if !it.IsThemesRepoInstalled(*config) {
 		// if the repo is missing install it using spinnerModel bubbletea functionality
@@ -28,7 +42,11 @@ func main() {
 	} else {
     // here, the repository is in place. Run the main model
     mainModel := models.InitializeMainModel(*config)
-    if _, err := tea.NewProgram(mainModel).Run(); err != nil {
+    var options []tea.ProgramOption
+    if config.UI.Mouse {
+      options = append(options, tea.WithMouseCellMotion())
+    }
+    if _, err := tea.NewProgram(mainModel, options...).Run(); err != nil {
       fmt.Println("Error running program:", err)
       os.Exit(1)
     }
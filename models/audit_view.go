@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"goalacritty_themes/palette"
+	it "goalacritty_themes/theme_tools"
+)
+
+var (
+	auditFailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	auditPassStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("150"))
+)
+
+// renderAuditPanel renders the readability audit of theme in place of the sample.
+func renderAuditPanel(theme it.ThemeData) string {
+	if theme.Palette == nil {
+		return "This theme could not be parsed."
+	}
+	audit := theme.Palette.Audit()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Score %.1f / 100, %d failing\n\n", audit.Score, len(audit.Failing()))
+	for _, r := range audit.Pairs {
+		swatch := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(r.Text.Hex())).
+			Background(lipgloss.Color(r.Background.Hex())).
+			Render(" Aa ")
+		result := auditPassStyle.Render("ok")
+		if !r.Pass() {
+			result = auditFailStyle.Render("fail")
+		}
+		fmt.Fprintf(&b, "%s %-15s %6.2f:1  Lc %6.1f  %s\n", swatch, r.Name, r.WCAG, r.APCA, result)
+	}
+	b.WriteString("\nRed/green/yellow distance\n")
+	for _, d := range palette.Deficiencies {
+		result := auditPassStyle.Render("ok")
+		if audit.CVD[d] < palette.MinConfusionDistance {
+			result = auditFailStyle.Render("fail")
+		}
+		fmt.Fprintf(&b, "     %-15s %6.1f             %s\n", d, audit.CVD[d], result)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"goalacritty_themes/palette"
+	it "goalacritty_themes/theme_tools"
+)
+
+const (
+	// maxCompared is the number of themes which can be marked for comparison.
+	maxCompared = 4
+	// diffThreshold is the OKLab distance above which two colors of a slot
+	// are shown as different.
+	diffThreshold = 2.0
+)
+
+var (
+	compareFrameStyle  = frameStyle.Margin(0, 1, 0, 0).Padding(0, 1)
+	compareFocusStyle  = compareFrameStyle.BorderForeground(lipgloss.Color("205"))
+	compareHeaderStyle = lipgloss.NewStyle().Bold(true)
+	compareDiffStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	compareSameStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// markIndex returns the position of theme among the marked themes, or -1.
+func (m model) markIndex(theme it.ThemeData) int {
+	for i, marked := range m.marked {
+		if marked.FullPath == theme.FullPath {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleMark marks the highlighted theme for comparison, or unmarks it.
+func (m *model) toggleMark() tea.Cmd {
+	theme, ok := m.highlightedTheme()
+	if !ok {
+		return nil
+	}
+	if i := m.markIndex(theme); i >= 0 {
+		m.marked = append(m.marked[:i:i], m.marked[i+1:]...)
+		return m.refreshItems()
+	}
+	if len(m.marked) == maxCompared {
+		return reportStatus("At most %d themes can be compared", maxCompared)
+	}
+	m.marked = append(m.marked, theme)
+	return tea.Batch(m.refreshItems(), reportStatus("Marked %s (%d/%d), press c to compare", theme.Name, len(m.marked), maxCompared))
+}
+
+// startComparison opens the comparison view on the marked themes.
+func (m *model) startComparison() tea.Cmd {
+	if len(m.marked) < 2 {
+		return reportStatus("Mark at least two themes with m to compare them")
+	}
+	m.comparing = true
+	m.compareFocus = 0
+	return m.previewCompared()
+}
+
+// previewCompared applies the focused theme of the comparison.
+func (m *model) previewCompared() tea.Cmd {
+	if err := m.preview(m.marked[m.compareFocus]); err != nil {
+		return reportError(err)
+	}
+	return nil
+}
+
+// updateComparison handles key presses while the comparison view is open.
+func (m model) updateComparison(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m.quit()
+	case key.Matches(msg, m.keys.Back, m.keys.Compare):
+		// Back to the list, previewing the highlighted theme again
+		m.comparing = false
+		if theme, ok := m.highlightedTheme(); ok {
+			if err := m.preview(theme); err != nil {
+				return m, reportError(err)
+			}
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Left):
+		m.compareFocus = (m.compareFocus + len(m.marked) - 1) % len(m.marked)
+		return m, m.previewCompared()
+	case key.Matches(msg, m.keys.Right):
+		m.compareFocus = (m.compareFocus + 1) % len(m.marked)
+		return m, m.previewCompared()
+	case key.Matches(msg, m.keys.NextScene):
+		m.scene = (m.scene + 1) % len(m.scenes)
+	case key.Matches(msg, m.keys.PrevScene):
+		m.scene = (m.scene + len(m.scenes) - 1) % len(m.scenes)
+	case key.Matches(msg, m.keys.Mark):
+		// Drop the focused theme from the comparison
+		m.marked = append(m.marked[:m.compareFocus:m.compareFocus], m.marked[m.compareFocus+1:]...)
+		m.compareFocus = 0
+		cmd := m.refreshItems()
+		if len(m.marked) < 2 {
+			m.comparing = false
+			return m, cmd
+		}
+		return m, tea.Batch(cmd, m.previewCompared())
+	case key.Matches(msg, m.keys.Choose):
+		// The focused theme is the winner
+		winner := m.marked[m.compareFocus]
+		m.choice = winner.Name
+		m.closeWatcher()
+		return m, m.chosen(winner)
+	}
+	return m, nil
+}
+
+// renderComparison renders the marked themes side by side, followed by a
+// table of their colors with the slots that differ highlighted.
+func (m model) renderComparison() string {
+	current := m.scenes[m.scene]
+	var columns []string
+	for i, theme := range m.marked {
+		title := theme.Name
+		style := compareFrameStyle
+		if i == m.compareFocus {
+			title = "> " + title
+			style = compareFocusStyle
+		}
+		sample := "This theme could not be parsed."
+		if theme.Palette != nil {
+			sample = renderScene(current, theme.Palette)
+		}
+		sample = clip(sample, m.compareColumnWidth(), m.compareSampleHeight())
+		columns = append(columns, style.Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			frameTitleStyle.Render(title),
+			sample,
+		)))
+	}
+	header := frameTitleStyle.Render(fmt.Sprintf("Comparing %d themes, scene: %s", len(m.marked), current.name))
+	k := m.keys
+	help := helpStyle.Render(fmt.Sprintf("%s/%s focus • %s pick • %s drop • %s/%s scene • %s back • %s help",
+		k.Left.Help().Key, k.Right.Help().Key, k.Choose.Help().Key, k.Mark.Help().Key,
+		k.NextScene.Help().Key, k.PrevScene.Help().Key, k.Back.Help().Key, k.Help.Help().Key))
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		lipgloss.JoinHorizontal(lipgloss.Top, columns...),
+		renderPaletteDiff(m.marked),
+		help,
+	)
+}
+
+// compareSampleHeight returns the height left for the samples by the header,
+// the frames, the palette table, the help and the status bar.
+func (m model) compareSampleHeight() int {
+	chrome := 1 + compareFrameStyle.GetVerticalFrameSize() + 1 + helpStyle.GetVerticalFrameSize() + 1 + statusHeight
+	table := slotCount + 1
+	return max(m.layout.height-chrome-table, minPreviewHeight)
+}
+
+// renderPaletteDiff renders one row per color slot and one column per theme.
+// Slots whose colors differ between the themes are highlighted, with the
+// largest distance between two of them.
+func renderPaletteDiff(themes []it.ThemeData) string {
+	slots := make([][]palette.Slot, len(themes))
+	for i, theme := range themes {
+		if theme.Palette != nil {
+			slots[i] = theme.Palette.Slots()
+		}
+	}
+	names := palette.Palette{}.Slots()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-16s", "")
+	for _, theme := range themes {
+		// Cut and pad by display width, names may be in any script
+		name := runewidth.Truncate(theme.Name, 12, "…")
+		b.WriteString(compareHeaderStyle.Render(runewidth.FillRight(name, 13)))
+	}
+	b.WriteString("\n")
+	for row, slot := range names {
+		var colors []*palette.Color
+		var cells strings.Builder
+		for i := range themes {
+			var c *palette.Color
+			if slots[i] != nil {
+				c = slots[i][row].Color
+			}
+			colors = append(colors, c)
+			if c == nil {
+				fmt.Fprintf(&cells, "%-13s", "   -")
+				continue
+			}
+			swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(c.Hex())).Render("██")
+			fmt.Fprintf(&cells, "%s %-10s", swatch, c.Hex())
+		}
+		spread, differs := colorSpread(colors)
+		label := compareSameStyle.Render(fmt.Sprintf("  %-16s", slot.Name))
+		suffix := ""
+		if differs {
+			label = compareDiffStyle.Render(fmt.Sprintf("≠ %-16s", slot.Name))
+			if spread > 0 {
+				suffix = compareDiffStyle.Render(fmt.Sprintf("ΔE %.1f", spread))
+			}
+		}
+		b.WriteString(label + cells.String() + suffix + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// colorSpread returns the largest distance between two of the colors, and
+// whether they differ noticeably. A slot that only some themes define differs.
+func colorSpread(colors []*palette.Color) (float64, bool) {
+	spread, defined := 0.0, 0
+	for i, c := range colors {
+		if c == nil {
+			continue
+		}
+		defined++
+		for _, d := range colors[i+1:] {
+			if d != nil {
+				spread = max(spread, palette.DeltaE(*c, *d))
+			}
+		}
+	}
+	return spread, spread > diffThreshold || (defined > 0 && defined < len(colors))
+}
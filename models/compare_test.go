@@ -0,0 +1,22 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	it "goalacritty_themes/theme_tools"
+)
+
+// TestRenderPaletteDiffNames tests that long theme names are cut by display
+// width without splitting characters.
+func TestRenderPaletteDiffNames(t *testing.T) {
+	themes := []it.ThemeData{{Name: "ソラリゼーション・ダーク"}, {Name: "café-crème-au-lait"}, {Name: "nord"}}
+	header, _, _ := strings.Cut(renderPaletteDiff(themes), "\n")
+	assert.True(t, utf8.ValidString(header))
+	assert.Contains(t, header, "ソラリゼー…")
+	assert.Contains(t, header, "café-crème-…")
+	assert.Equal(t, 2+16+3*13, lipgloss.Width(header))
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"goalacritty_themes/palette"
+	it "goalacritty_themes/theme_tools"
+)
+
+// slotCount is the number of color slots of a palette.
+var slotCount = len(palette.Palette{}.Slots())
+
+var (
+	detailCursorStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("150"))
+	detailUnsetStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// renderDetailPanel lists every color slot of theme, with the slot under the
+// cursor highlighted.
+func renderDetailPanel(theme it.ThemeData, cursor int, keys keyMap) string {
+	if theme.Palette == nil {
+		return "This theme could not be parsed."
+	}
+	var b strings.Builder
+	for i, slot := range theme.Palette.Slots() {
+		marker, name := "  ", fmt.Sprintf("%-15s", slot.Name)
+		if i == cursor {
+			marker, name = detailCursorStyle.Render("> "), detailCursorStyle.Render(name)
+		}
+		if slot.Color == nil {
+			fmt.Fprintf(&b, "%s%s %s\n", marker, name, detailUnsetStyle.Render("     not set, uses the cell colors"))
+			continue
+		}
+		c := *slot.Color
+		h, s, l := c.HSL()
+		swatch := lipgloss.NewStyle().Background(lipgloss.Color(c.Hex())).Render("    ")
+		fmt.Fprintf(&b, "%s%s %s %s  rgb(%3d, %3d, %3d)  hsl(%3.0f, %3.0f%%, %3.0f%%)\n",
+			marker, name, swatch, c.Hex(), c.R, c.G, c.B, h, s, l)
+	}
+	b.WriteString(helpStyle.Render(fmt.Sprintf("%s/%s color • %s copy value • %s copy theme file • %s close",
+		keys.PrevSlot.Help().Key, keys.NextSlot.Help().Key, keys.CopyValue.Help().Key, keys.CopyFile.Help().Key, keys.Palette.Help().Key)))
+	return b.String()
+}
+
+// moveSlot moves the cursor of the palette pane by step slots, scrolling the
+// pane to keep the cursor visible.
+func (m *model) moveSlot(step int) {
+	m.detailSlot = (m.detailSlot + step) % slotCount
+	if m.detailSlot < m.sampleScroll {
+		m.sampleScroll = m.detailSlot
+	} else if m.detailSlot >= m.sampleScroll+m.layout.previewHeight {
+		m.sampleScroll = m.detailSlot - m.layout.previewHeight + 1
+	}
+}
+
+// copySlot copies the hex value of the slot under the cursor.
+func (m model) copySlot() tea.Cmd {
+	theme, ok := m.highlightedTheme()
+	if !ok || theme.Palette == nil {
+		return nil
+	}
+	slot := theme.Palette.Slots()[m.detailSlot]
+	if slot.Color == nil {
+		return reportStatus("%s is not set in %s", slot.Name, theme.Name)
+	}
+	return copyToClipboard(slot.Name+" "+slot.Color.Hex(), slot.Color.Hex())
+}
+
+// copyThemeFile copies the content of the highlighted theme's file.
+func (m model) copyThemeFile() tea.Cmd {
+	theme, ok := m.highlightedTheme()
+	if !ok {
+		return nil
+	}
+	content, err := os.ReadFile(theme.FullPath)
+	if err != nil {
+		return reportError(err)
+	}
+	return copyToClipboard(theme.Name+".toml", string(content))
+}
+
+// copyToClipboard sets the clipboard to text. It writes an OSC 52 escape
+// sequence, which the terminal turns into a clipboard update even when the
+// picker runs over SSH, and also sets the local clipboard when there is one.
+// It is called from Update, so that the sequence is written at once to the
+// terminal rather than from a command running next to the renderer.
+func copyToClipboard(what, text string) tea.Cmd {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	if err := writeToTerminal(seq.String()); err != nil {
+		return reportError(fmt.Errorf("could not copy to the clipboard: %w", err))
+	}
+	return func() tea.Msg {
+		// Without a local clipboard (e.g. over SSH) the escape sequence is enough
+		_ = clipboard.WriteAll(text)
+		return statusMsg("Copied " + what)
+	}
+}
+
+// writeToTerminal writes s to the controlling terminal in a single write.
+func writeToTerminal(s string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString(s)
+	return err
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	it "goalacritty_themes/theme_tools"
+)
+
+// section selects which themes the list shows.
+type section int
+
+const (
+	sectionAll section = iota
+	sectionFavorites
+	sectionRecent
+	sectionCount
+)
+
+func (s section) String() string {
+	switch s {
+	case sectionFavorites:
+		return "Favorites"
+	case sectionRecent:
+		return "Recent"
+	}
+	return "Select a Theme"
+}
+
+// sectionThemes returns the themes of the current section, in the section's order.
+func (m model) sectionThemes() []it.ThemeData {
+	if m.section == sectionAll || m.store == nil {
+		return m.themes
+	}
+	names := m.store.Favorites
+	if m.section == sectionRecent {
+		names = m.store.RecentThemes()
+	}
+	byName := map[string]it.ThemeData{}
+	for _, theme := range m.themes {
+		byName[theme.Name] = theme
+	}
+	var themes []it.ThemeData
+	for _, name := range names {
+		if theme, ok := byName[name]; ok {
+			themes = append(themes, theme)
+		}
+	}
+	return themes
+}
+
+// saveStore persists the favorites, tags and recent themes after a change.
+func (m model) saveStore() tea.Cmd {
+	if err := m.store.Save(); err != nil {
+		return reportError(err)
+	}
+	return nil
+}
+
+// toggleFavorite stars or unstars the highlighted theme.
+func (m *model) toggleFavorite() tea.Cmd {
+	theme, ok := m.highlightedTheme()
+	if !ok {
+		return nil
+	}
+	status := reportStatus("Removed %s from the favorites", theme.Name)
+	if m.store.ToggleFavorite(theme.Name) {
+		status = reportStatus("Added %s to the favorites", theme.Name)
+	}
+	return tea.Batch(m.saveStore(), m.refreshItems(), status)
+}
+
+// recordUse adds the theme to the recently used themes.
+func (m model) recordUse(theme string) tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	m.store.Touch(theme, time.Now())
+	return m.saveStore()
+}
+
+// startTagging opens the prompt for tagging the highlighted theme.
+func (m *model) startTagging() tea.Cmd {
+	theme, ok := m.highlightedTheme()
+	if !ok {
+		return nil
+	}
+	m.tagInput = textinput.New()
+	m.tagInput.Prompt = "Tags for " + theme.Name + ": "
+	m.tagInput.SetValue(strings.Join(m.store.TagsOf(theme.Name), " "))
+	m.tagging = true
+	return m.tagInput.Focus()
+}
+
+// updateTagging handles key presses while the tag prompt is open.
+func (m model) updateTagging(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.tagging = false
+		return m, nil
+	case "enter":
+		m.tagging = false
+		theme, ok := m.highlightedTheme()
+		if !ok {
+			return m, nil
+		}
+		// The prompt starts with the current tags, so tags removed from it
+		// are removed from the theme as well
+		m.store.RemoveTags(theme.Name, m.store.TagsOf(theme.Name)...)
+		m.store.AddTags(theme.Name, strings.Fields(m.tagInput.Value())...)
+		return m, tea.Batch(m.saveStore(), m.refreshItems())
+	}
+	var cmd tea.Cmd
+	m.tagInput, cmd = m.tagInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	it "goalacritty_themes/theme_tools"
+)
+
+// queryFilter returns a list filter understanding the query language of
+// it.ParseQuery: palette terms are evaluated against an index over themes,
+// and the remaining words are fuzzy matched against the titles like the
+// list's default filter does. A query that does not parse yet, like a half
+// typed "hue:b", is fuzzy matched as a whole instead of emptying the list.
+// themes must be in the same order as the items.
+func queryFilter(themes []it.ThemeData) list.FilterFunc {
+	index := it.NewQueryIndex(themes)
+	return func(term string, targets []string) []list.Rank {
+		q, err := it.ParseQuery(term)
+		if err != nil {
+			return list.DefaultFilter(term, targets)
+		}
+		matches := index.Match(q)
+		if q.Name == "" {
+			ranks := make([]list.Rank, len(matches))
+			for i, idx := range matches {
+				ranks[i] = list.Rank{Index: idx}
+			}
+			return ranks
+		}
+
+		subset := make([]string, len(matches))
+		for i, idx := range matches {
+			subset[i] = targets[idx]
+		}
+		ranks := list.DefaultFilter(q.Name, subset)
+		for i := range ranks {
+			ranks[i].Index = matches[ranks[i].Index]
+		}
+		return ranks
+	}
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// keyMap holds the bindings of every action of the picker. The defaults
+// follow vim; each action can be rebound from the [keys] section of the
+// config, e.g. `favorite = ["f", "*"]`. ctrl+c always quits.
+type keyMap struct {
+	// Moving through the list
+	Up, Down, PageUp, PageDown, Top, Bottom, Filter key.Binding
+
+	Quit, Choose, Help key.Binding
+
+	// The list and the sample panel
+	Class, Audit, Palette, PrevSlot, NextSlot, CopyValue, CopyFile key.Binding
+	Simulate, Similar, NextScene, PrevScene, Mark, Compare         key.Binding
+	Favorite, Tag, Section                                         key.Binding
+
+	// The comparison view
+	Left, Right, Back key.Binding
+}
+
+// action is a named binding of the keymap.
+type action struct {
+	name    string // the name used in the [keys] section
+	binding *key.Binding
+}
+
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), desc))
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:       binding("up", "k", "up"),
+		Down:     binding("down", "j", "down"),
+		PageUp:   binding("previous page", "ctrl+u", "pgup"),
+		PageDown: binding("next page", "ctrl+d", "pgdown"),
+		Top:      binding("first theme", "g", "home"),
+		Bottom:   binding("last theme", "G", "end"),
+		Filter:   binding("filter", "/"),
+
+		Quit:   binding("quit, restoring the theme", "q", "ctrl+c"),
+		Choose: binding("choose the theme", "enter"),
+		Help:   binding("toggle help", "?"),
+
+		Class:     binding("all / dark / light themes", "d"),
+		Audit:     binding("readability audit", "a"),
+		Palette:   binding("palette pane", "p"),
+		PrevSlot:  binding("previous color (palette pane)", "["),
+		NextSlot:  binding("next color (palette pane)", "]"),
+		CopyValue: binding("copy the color (palette pane)", "y"),
+		CopyFile:  binding("copy the theme file (palette pane)", "Y"),
+		Simulate:  binding("color vision simulation", "v"),
+		Similar:   binding("order by similarity", "s"),
+		NextScene: binding("next scene", "n"),
+		PrevScene: binding("previous scene", "N"),
+		Mark:      binding("mark for comparison", "m"),
+		Compare:   binding("compare the marked themes", "c"),
+		Favorite:  binding("toggle favorite", "f"),
+		Tag:       binding("edit tags", "t"),
+		Section:   binding("all / favorites / recent", "tab"),
+
+		Left:  binding("previous theme (comparison)", "h", "left", "shift+tab"),
+		Right: binding("next theme (comparison)", "l", "right", "tab"),
+		Back:  binding("back to the list (comparison)", "esc"),
+	}
+}
+
+// pickerActions are the actions available while browsing the list.
+func (k *keyMap) pickerActions() []action {
+	return []action{
+		{"up", &k.Up}, {"down", &k.Down}, {"page_up", &k.PageUp}, {"page_down", &k.PageDown},
+		{"top", &k.Top}, {"bottom", &k.Bottom}, {"filter", &k.Filter},
+		{"quit", &k.Quit}, {"choose", &k.Choose}, {"help", &k.Help},
+		{"class", &k.Class}, {"audit", &k.Audit}, {"palette", &k.Palette},
+		{"prev_slot", &k.PrevSlot}, {"next_slot", &k.NextSlot},
+		{"copy_value", &k.CopyValue}, {"copy_file", &k.CopyFile},
+		{"simulate", &k.Simulate}, {"similar", &k.Similar},
+		{"next_scene", &k.NextScene}, {"prev_scene", &k.PrevScene},
+		{"mark", &k.Mark}, {"compare", &k.Compare},
+		{"favorite", &k.Favorite}, {"tag", &k.Tag}, {"section", &k.Section},
+	}
+}
+
+// compareActions are the actions available in the comparison view.
+func (k *keyMap) compareActions() []action {
+	return []action{
+		{"quit", &k.Quit}, {"choose", &k.Choose}, {"help", &k.Help},
+		{"left", &k.Left}, {"right", &k.Right}, {"back", &k.Back},
+		{"next_scene", &k.NextScene}, {"prev_scene", &k.PrevScene},
+		{"mark", &k.Mark}, {"compare", &k.Compare},
+	}
+}
+
+// newKeyMap returns the default keymap with the bindings of overrides, which
+// maps action names to keys. Unknown actions and overrides binding a key to
+// two actions of the same view are reported; the actions involved keep their
+// default keys while the other overrides apply.
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	k, defaults := defaultKeyMap(), defaultKeyMap()
+	actions, defaultActions := map[string]*key.Binding{}, map[string]*key.Binding{}
+	for _, a := range append(k.pickerActions(), k.compareActions()...) {
+		actions[a.name] = a.binding
+	}
+	for _, a := range append(defaults.pickerActions(), defaults.compareActions()...) {
+		defaultActions[a.name] = a.binding
+	}
+	var problems []string
+	overridden := map[string]bool{}
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown action %q", name))
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+		overridden[name] = true
+	}
+	// Restoring a default can clash with another override, so repeat until
+	// no override is involved in a conflict. The defaults do not conflict.
+	for {
+		reverted := false
+		for _, c := range append(conflicts(k.pickerActions()), conflicts(k.compareActions())...) {
+			problems = append(problems, c.String())
+			for _, name := range []string{c.first, c.second} {
+				if overridden[name] {
+					*actions[name] = *defaultActions[name]
+					overridden[name] = false
+					reverted = true
+				}
+			}
+		}
+		if !reverted {
+			break
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		problems = slices.Compact(problems)
+		return k, fmt.Errorf("invalid [keys] config, the actions involved keep their default keys: %s", strings.Join(problems, "; "))
+	}
+	return k, nil
+}
+
+// conflict is a key bound to two actions of a view.
+type conflict struct {
+	key, first, second string
+}
+
+func (c conflict) String() string {
+	return fmt.Sprintf("%q is bound to both %s and %s", c.key, c.first, c.second)
+}
+
+// conflicts returns the keys bound to more than one of actions.
+func conflicts(actions []action) []conflict {
+	bound := map[string]string{}
+	var found []conflict
+	for _, a := range actions {
+		for _, k := range a.binding.Keys() {
+			if other, ok := bound[k]; ok {
+				found = append(found, conflict{k, other, a.name})
+				continue
+			}
+			bound[k] = a.name
+		}
+	}
+	return found
+}
+
+// applyToList makes the list use the navigation bindings. Quitting and help
+// are handled by the picker.
+func (k keyMap) applyToList(l *list.Model) {
+	l.KeyMap.CursorUp = k.Up
+	l.KeyMap.CursorDown = k.Down
+	l.KeyMap.PrevPage = k.PageUp
+	l.KeyMap.NextPage = k.PageDown
+	l.KeyMap.GoToStart = k.Top
+	l.KeyMap.GoToEnd = k.Bottom
+	l.KeyMap.Filter = k.Filter
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{k.Choose, k.Help}
+	}
+}
+
+// renderHelp renders every action with its keys.
+func (k keyMap) renderHelp() string {
+	h := help.New()
+	h.ShowAll = true
+	return h.FullHelpView([][]key.Binding{
+		{k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom, k.Filter, k.Choose, k.Quit, k.Help},
+		{k.Class, k.Section, k.Similar, k.Favorite, k.Tag, k.Mark, k.Compare},
+		{k.NextScene, k.PrevScene, k.Audit, k.Simulate, k.Palette, k.PrevSlot, k.NextSlot, k.CopyValue, k.CopyFile},
+		{k.Left, k.Right, k.Back},
+	})
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewKeyMap tests that conflicting overrides fall back to the default
+// keys while the other overrides apply.
+func TestNewKeyMap(t *testing.T) {
+	k, err := newKeyMap(map[string][]string{"favorite": {"*"}, "quit": {"q", "ctrl+q"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*"}, k.Favorite.Keys())
+	assert.Equal(t, []string{"q", "ctrl+q"}, k.Quit.Keys())
+
+	// esc is already bound to back in the comparison view
+	k, err = newKeyMap(map[string][]string{"favorite": {"*"}, "quit": {"q", "esc"}, "bogus": {"x"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"esc" is bound to both quit and back`)
+	assert.Contains(t, err.Error(), `unknown action "bogus"`)
+	assert.Equal(t, []string{"*"}, k.Favorite.Keys())
+	assert.Equal(t, defaultKeyMap().Quit.Keys(), k.Quit.Keys())
+	assert.Equal(t, defaultKeyMap().Back.Keys(), k.Back.Keys())
+
+	// Restoring quit's default "q" clashes with tag, which is restored too
+	k, err = newKeyMap(map[string][]string{"quit": {"f"}, "tag": {"q"}})
+	require.Error(t, err)
+	assert.Equal(t, defaultKeyMap().Quit.Keys(), k.Quit.Keys())
+	assert.Equal(t, defaultKeyMap().Tag.Keys(), k.Tag.Keys())
+	assert.Equal(t, defaultKeyMap().Favorite.Keys(), k.Favorite.Keys())
+}
@@ -0,0 +1,106 @@
+package models
+
+import "github.com/charmbracelet/lipgloss"
+
+// layoutMode is how the list and the sample panel share the window.
+type layoutMode int
+
+const (
+	layoutSideBySide layoutMode = iota // list on the left, sample on the right
+	layoutStacked                      // list above the sample
+	layoutListOnly                     // no room for the sample
+)
+
+const (
+	// The size assumed until the terminal reports its own
+	defaultWidth  = 100
+	defaultHeight = 24
+
+	minListWidth      = 30
+	maxListWidth      = 50
+	minListHeight     = 8
+	minPreviewWidth   = 40
+	minPreviewHeight  = 5
+	statusHeight      = 1
+	minCompareColumns = 12
+)
+
+// layout holds the sizes of the parts of the picker for a window size. The
+// preview sizes are those of the sample's content, inside its frame.
+type layout struct {
+	mode                        layoutMode
+	width, height               int
+	listWidth, listHeight       int
+	previewWidth, previewHeight int
+}
+
+// frameSize returns the width and height taken by the sample frame around
+// its content, including the title line.
+func frameSize() (int, int) {
+	return frameStyle.GetHorizontalFrameSize(), frameStyle.GetVerticalFrameSize() + 1
+}
+
+// computeLayout places the list and the sample in a window of the given size.
+// The sample goes next to the list when the window is wide enough and tall
+// enough for the sample, below it when it is tall enough for both, and is
+// hidden otherwise.
+func computeLayout(width, height int) layout {
+	frameWidth, frameHeight := frameSize()
+	l := layout{width: width, height: height}
+	available := max(height-statusHeight, 1)
+
+	switch {
+	case width >= minListWidth+minPreviewWidth+frameWidth && available >= minPreviewHeight+frameHeight:
+		l.mode = layoutSideBySide
+		l.listWidth = min(max(width*2/5, minListWidth), maxListWidth, width-minPreviewWidth-frameWidth)
+		l.listHeight = available
+		l.previewWidth = width - l.listWidth - frameWidth
+		l.previewHeight = max(available-frameHeight, 0)
+	case available >= minListHeight+minPreviewHeight+frameHeight:
+		l.mode = layoutStacked
+		l.listWidth = width
+		l.listHeight = max(min(available/2, available-minPreviewHeight-frameHeight), minListHeight)
+		l.previewWidth = max(width-frameWidth, 0)
+		l.previewHeight = available - l.listHeight - frameHeight
+	default:
+		l.mode = layoutListOnly
+		l.listWidth = width
+		l.listHeight = available
+	}
+	return l
+}
+
+// clip cuts text to the given size. Nothing fits in an empty size, while
+// lipgloss would take 0 as no limit.
+func clip(text string, width, height int) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().MaxWidth(width).MaxHeight(height).Render(text)
+}
+
+// resize applies a new window size.
+func (m *model) resize(width, height int) {
+	m.layout = computeLayout(width, height)
+	m.list.SetSize(m.layout.listWidth, m.layout.listHeight)
+	// The list sizes its help to the full width, without its padding
+	m.list.Help.Width = max(m.layout.listWidth-helpStyle.GetHorizontalFrameSize(), 0)
+}
+
+// arrange joins the list and the sample frame according to the layout.
+func (m model) arrange(list, sample string) string {
+	switch m.layout.mode {
+	case layoutStacked:
+		return lipgloss.JoinVertical(lipgloss.Left, list, sample)
+	case layoutListOnly:
+		return list
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, sample)
+}
+
+// compareColumnWidth returns the width of each theme's sample in the
+// comparison view.
+func (m model) compareColumnWidth() int {
+	columns := max(len(m.marked), 1)
+	return max(m.layout.width/columns-compareFrameStyle.GetHorizontalFrameSize(), minCompareColumns)
+}
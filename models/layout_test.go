@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	it "goalacritty_themes/theme_tools"
+)
+
+// TestComputeLayout tests the layout modes around their cutoffs and in
+// tiny terminals.
+func TestComputeLayout(t *testing.T) {
+	frameWidth, frameHeight := frameSize()
+	sideBySide := minListWidth + minPreviewWidth + frameWidth
+	stacked := minListHeight + minPreviewHeight + frameHeight + statusHeight
+
+	for _, c := range []struct {
+		name          string
+		width, height int
+		want          layout
+	}{
+		{"default", defaultWidth, defaultHeight, layout{layoutSideBySide, 100, 24, 40, 23, 52, 16}},
+		{"wide", 200, 50, layout{layoutSideBySide, 200, 50, maxListWidth, 49, 200 - maxListWidth - frameWidth, 49 - frameHeight}},
+		{"narrowest side by side", sideBySide, 24, layout{layoutSideBySide, sideBySide, 24, minListWidth, 23, minPreviewWidth, 23 - frameHeight}},
+		{"side by side, a few columns more", sideBySide + 3, 24, layout{layoutSideBySide, sideBySide + 3, 24, minListWidth + 2, 23, minPreviewWidth + 1, 23 - frameHeight}},
+		{"lowest side by side", 120, minPreviewHeight + frameHeight + statusHeight, layout{layoutSideBySide, 120, minPreviewHeight + frameHeight + statusHeight, 48, minPreviewHeight + frameHeight, 120 - 48 - frameWidth, minPreviewHeight}},
+		{"wide and flat", 120, 6, layout{layoutListOnly, 120, 6, 120, 5, 0, 0}},
+		{"stacked", sideBySide - 1, 24, layout{layoutStacked, sideBySide - 1, 24, sideBySide - 1, 11, sideBySide - 1 - frameWidth, 23 - 11 - frameHeight}},
+		{"lowest stacked", 60, stacked, layout{layoutStacked, 60, stacked, 60, minListHeight, 60 - frameWidth, minPreviewHeight}},
+		{"list only", 60, stacked - 1, layout{layoutListOnly, 60, stacked - 1, 60, stacked - 1 - statusHeight, 0, 0}},
+		{"tiny", 10, 1, layout{layoutListOnly, 10, 1, 10, 1, 0, 0}},
+		{"empty", 0, 0, layout{layoutListOnly, 0, 0, 0, 1, 0, 0}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			l := computeLayout(c.width, c.height)
+			assert.Equal(t, c.want, l)
+			switch l.mode {
+			case layoutSideBySide:
+				assert.Equal(t, c.width, l.listWidth+l.previewWidth+frameWidth)
+				assert.GreaterOrEqual(t, l.previewWidth, minPreviewWidth)
+				assert.GreaterOrEqual(t, l.previewHeight, minPreviewHeight)
+			case layoutStacked:
+				assert.Equal(t, c.height-statusHeight, l.listHeight+l.previewHeight+frameHeight)
+				assert.GreaterOrEqual(t, l.previewHeight, minPreviewHeight)
+			}
+		})
+	}
+}
+
+// TestClip tests that text is cut to the given size.
+func TestClip(t *testing.T) {
+	assert.Equal(t, "abc\nghi", clip("abcdef\nghi\njkl", 3, 2))
+	assert.Equal(t, "ab", clip("ab", 10, 10))
+	assert.Equal(t, "a\nd", clip("abc\ndef", 1, 5))
+	assert.Equal(t, "", clip("abc\ndef", 3, 0))
+	assert.Equal(t, "", clip("abc\ndef", -1, 5))
+}
+
+// TestCompareColumnWidth tests that the comparison columns share the width,
+// down to a minimum.
+func TestCompareColumnWidth(t *testing.T) {
+	frame := compareFrameStyle.GetHorizontalFrameSize()
+	for _, c := range []struct {
+		width, marked, want int
+	}{
+		{100, 0, 100 - frame},
+		{100, 2, 50 - frame},
+		{100, 4, 25 - frame},
+		{100, 10, minCompareColumns},
+		{10, 2, minCompareColumns},
+	} {
+		m := model{layout: computeLayout(c.width, defaultHeight), marked: make([]it.ThemeData, c.marked)}
+		assert.Equal(t, c.want, m.compareColumnWidth(), "%d columns, %d themes", c.width, c.marked)
+	}
+}
@@ -1,22 +1,25 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"goalacritty_themes/applier"
 	cf "goalacritty_themes/config"
+	"goalacritty_themes/hooks"
+	"goalacritty_themes/palette"
+	"goalacritty_themes/state"
 	it "goalacritty_themes/theme_tools"
-)
-
-const (
-	listHeight      = 14
-	listWidth       = 30
-	sampleTextWidth = 50
+	"goalacritty_themes/watcher"
 )
 
 var (
@@ -32,6 +35,11 @@ var (
 
 type item struct {
 	title, desc string
+	theme       it.ThemeData
+	favorite    bool
+	tags        []string
+	active      bool // the theme imported by the alacritty config when the picker started
+	mark        int  // position among the themes marked for comparison, 0 if unmarked
 }
 
 func (i item) FilterValue() string { return i.title }
@@ -48,6 +56,18 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	str := fmt.Sprintf("%d. %s", index+1, i.title)
+	if i.active {
+		str += " (active)"
+	}
+	if i.mark > 0 {
+		str += fmt.Sprintf(" [%d]", i.mark)
+	}
+	if i.favorite {
+		str += " ★"
+	}
+	for _, tag := range i.tags {
+		str += " #" + tag
+	}
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -69,120 +89,502 @@ type model struct {
 	config        cf.Config
 	previousIndex int
 	currentTheme  it.ThemeData
-	sampleText    string
+	scenes        []scene
+	scene         int    // index of the scene shown in the sample frame
+	err           error  // shown in the error modal until dismissed
+	status        string // last message shown in the status bar
+	statusIsErr   bool
+	themes        []it.ThemeData // every installed theme, before filtering
+	classFilter   palette.Class  // Unknown shows both dark and light themes
+	showAudit     bool           // show the readability audit instead of the sample
+	showDetail    bool           // show the colors of the highlighted theme instead of the sample
+	detailSlot    int            // color slot under the cursor in the detail pane
+	simulation    palette.Deficiency
+	similarity    *it.SimilarityIndex
+	similarTo     string // when set, the list is ordered by similarity to this theme
+	watcher       *watcher.Watcher
+	previewed     string          // path of the theme last written to the alacritty config
+	applier       applier.Applier // shows the previewed themes, see the [apply] config
+	hooks         []hooks.Hook    // run once a theme is chosen
+	hooksErr      error           // failures of the hooks, shown after quitting
+	store         *state.Store    // favorites, tags and recent themes; nil if it could not be loaded
+	section       section
+	tagging       bool // the tag prompt is open
+	tagInput      textinput.Model
+	marked        []it.ThemeData // themes marked for comparison
+	comparing     bool           // the comparison view is open
+	compareFocus  int            // index in marked of the theme under the cursor
+	keys          keyMap
+	showHelp      bool // the help overlay is open
+	layout        layout
+	sampleScroll  int // lines scrolled out of the top of the sample panel
+
+	// The last click on the list, to detect double clicks
+	lastClickIndex int
+	lastClickAt    time.Time
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return waitForChanges(m.watcher)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.list.SetWidth(msg.Width - sampleTextWidth - 4) // Adjust list width for the sample text
+		m.resize(msg.Width, msg.Height)
+		return m, nil
+
+	case statusMsg:
+		m.status, m.statusIsErr = string(msg), false
+		return m, nil
+
+	case errMsg:
+		m.err = msg.err
+		m.status, m.statusIsErr = msg.err.Error(), true
+		return m, nil
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
+	case watchMsg:
+		return m.handleChanges(msg)
+
+	case hooksFailedMsg:
+		m.hooksErr = msg.err
 		return m, nil
 
+	case themesLoadedMsg:
+		if msg.err != nil {
+			return m, reportError(msg.err)
+		}
+		m.err = nil
+		m.themes = msg.themes
+		m.similarity = it.NewSimilarityIndex(msg.themes)
+		return m, tea.Batch(m.refreshItems(), reportStatus("Loaded %d themes", len(msg.themes)))
+
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "q", "ctrl+c":
-			m.quitting = true
-			return m, tea.Quit
-
-		case "enter":
-			i, ok := m.list.SelectedItem().(item)
-			if ok {
-				m.choice = i.title
+		if m.err != nil {
+			return m.updateErrorModal(msg)
+		}
+		if msg.Type == tea.KeyCtrlC {
+			return m.quit()
+		}
+		if m.tagging {
+			return m.updateTagging(msg)
+		}
+		if m.showHelp {
+			if key.Matches(msg, m.keys.Quit) {
+				return m.quit()
+			}
+			if key.Matches(msg, m.keys.Help, m.keys.Back) {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+		if m.list.FilterState() == list.Filtering {
+			// Keys are typed into the filter
+			break
+		}
+		if key.Matches(msg, m.keys.Help) {
+			m.showHelp = true
+			return m, nil
+		}
+		if m.comparing {
+			return m.updateComparison(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit()
+
+		case key.Matches(msg, m.keys.Choose):
+			theme, _ := m.highlightedTheme()
+			m.choice = theme.Name
+			m.closeWatcher()
+			return m, m.chosen(theme)
+
+		case key.Matches(msg, m.keys.Class):
+			// Cycle through all themes, dark themes only and light themes only
+			m.classFilter = (m.classFilter + 1) % 3
+			return m, m.refreshItems()
+
+		case key.Matches(msg, m.keys.Audit):
+			m.showAudit = !m.showAudit
+			m.showDetail = false
+			return m, nil
+
+		case key.Matches(msg, m.keys.Palette):
+			m.showDetail = !m.showDetail
+			m.showAudit = false
+			return m, nil
+
+		case m.showDetail && key.Matches(msg, m.keys.PrevSlot):
+			m.moveSlot(slotCount - 1)
+			return m, nil
+
+		case m.showDetail && key.Matches(msg, m.keys.NextSlot):
+			m.moveSlot(1)
+			return m, nil
+
+		case m.showDetail && key.Matches(msg, m.keys.CopyValue):
+			return m, m.copySlot()
+
+		case m.showDetail && key.Matches(msg, m.keys.CopyFile):
+			return m, m.copyThemeFile()
+
+		case key.Matches(msg, m.keys.Simulate):
+			// Cycle through normal vision and the simulated deficiencies
+			m.simulation = (m.simulation + 1) % palette.Deficiency(len(palette.Deficiencies)+1)
+			return m, nil
+
+		case key.Matches(msg, m.keys.Similar):
+			// Toggle ordering the list by similarity to the highlighted theme
+			if m.similarTo != "" {
+				m.similarTo = ""
+			} else if theme, ok := m.highlightedTheme(); ok {
+				m.similarTo = theme.Name
 			}
-			return m, tea.Quit
+			return m, m.refreshItems()
+
+		case key.Matches(msg, m.keys.NextScene):
+			m.scene = (m.scene + 1) % len(m.scenes)
+			return m, nil
+
+		case key.Matches(msg, m.keys.PrevScene):
+			m.scene = (m.scene + len(m.scenes) - 1) % len(m.scenes)
+			return m, nil
+
+		case key.Matches(msg, m.keys.Mark):
+			return m, m.toggleMark()
+
+		case key.Matches(msg, m.keys.Compare):
+			return m, m.startComparison()
+
+		case key.Matches(msg, m.keys.Favorite, m.keys.Tag, m.keys.Section):
+			if m.store == nil {
+				return m, reportStatus("Favorites and tags are not available")
+			}
+			switch {
+			case key.Matches(msg, m.keys.Favorite):
+				return m, m.toggleFavorite()
+			case key.Matches(msg, m.keys.Tag):
+				return m, m.startTagging()
+			}
+			// Cycle through all themes, the favorites and the recent themes
+			m.section = (m.section + 1) % sectionCount
+			return m, m.refreshItems()
 		}
 	}
 
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
+	return m, tea.Batch(cmd, m.previewSelection())
+}
 
-	// Detect if the highlighted item has changed
+// previewSelection previews the highlighted theme if it changed.
+func (m *model) previewSelection() tea.Cmd {
 	currentIndex := m.list.Index()
-	if currentIndex != m.previousIndex {
-		i, ok := m.list.SelectedItem().(item)
-		if ok {
-			themeData := it.ThemeData{
-				Name:     i.title,
-				FullPath: i.desc,
+	if currentIndex == m.previousIndex {
+		return nil
+	}
+	m.previousIndex = currentIndex
+	if themeData, ok := m.highlightedTheme(); ok {
+		if err := m.preview(themeData); err != nil {
+			return reportError(err)
+		}
+	}
+	return nil
+}
+
+// errNotRestored is shown when quitting could not restore the theme. Quitting
+// again from its error modal gives up on restoring.
+var errNotRestored = errors.New("could not restore the previous theme")
+
+// quit restores the theme that was active before the picker started and quits.
+func (m model) quit() (tea.Model, tea.Cmd) {
+	if err := m.applier.Revert(); err != nil && !errors.Is(m.err, errNotRestored) {
+		return m, reportError(fmt.Errorf("%w: %w", errNotRestored, err))
+	}
+	m.quitting = true
+	m.closeWatcher()
+	return m, tea.Quit
+}
+
+// updateErrorModal handles key presses while the error modal is shown.
+func (m model) updateErrorModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyCtrlC, key.Matches(msg, m.keys.Quit):
+		return m.quit()
+	case msg.Type == tea.KeyEsc:
+		m.err = nil
+		return m, nil
+	}
+	for _, r := range recoveriesFor(m.err) {
+		if msg.String() == r.key {
+			theme, _ := m.highlightedTheme()
+			m.err = nil
+			m.status, m.statusIsErr = "Trying to "+r.label+"...", false
+			return m, r.run(m.config, theme)
+		}
+	}
+	return m, nil
+}
+
+func (m model) closeWatcher() {
+	if m.watcher != nil {
+		m.watcher.Close()
+	}
+}
+
+// highlightedTheme returns the theme under the cursor.
+func (m model) highlightedTheme() (it.ThemeData, bool) {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return it.ThemeData{}, false
+	}
+	return i.theme, true
+}
+
+// refreshItems rebuilds the list from m.themes, applying the class filter
+// and the similarity ordering.
+func (m *model) refreshItems() tea.Cmd {
+	themes := m.sectionThemes()
+	m.list.Title = m.section.String()
+	if m.similarTo != "" {
+		matches, err := m.similarity.Similar(m.similarTo)
+		if err != nil {
+			m.similarTo = ""
+			return reportError(err)
+		}
+		inSection := map[string]bool{}
+		for _, theme := range themes {
+			inSection[theme.FullPath] = true
+		}
+		m.list.Title = "Similar to " + m.similarTo
+		themes = nil
+		for _, theme := range m.themes {
+			if theme.Name == m.similarTo {
+				themes = append(themes, theme)
+			}
+		}
+		for _, match := range matches {
+			if inSection[match.Theme.FullPath] {
+				themes = append(themes, match.Theme)
 			}
-			it.UpdateAlacrittyConfigFile(m.config, themeData)
 		}
-		m.previousIndex = currentIndex
 	}
+	if m.classFilter != palette.Unknown {
+		m.list.Title += " (" + m.classFilter.String() + ")"
+	}
+	selected, hadSelection := m.highlightedTheme()
+	if !hadSelection {
+		// Start on the active theme
+		selected, hadSelection = m.currentTheme, m.currentTheme.FullPath != ""
+	}
+	themes = it.FilterByClass(themes, m.classFilter)
+	m.list.Filter = queryFilter(themes)
+	cmd := m.list.SetItems(m.themeItems(themes))
+
+	// Keep the cursor on the same theme if it is still listed
+	m.list.Select(0)
+	m.previousIndex = -1
+	for i, theme := range themes {
+		if hadSelection && theme.FullPath == selected.FullPath {
+			m.list.Select(i)
+			m.previousIndex = i
+			break
+		}
+	}
+	return cmd
+}
 
-	return m, cmd
+// preview shows theme with the applier, making sure the theme file exists first.
+func (m *model) preview(theme it.ThemeData) error {
+	if err := it.CheckTheme(theme); err != nil {
+		return err
+	}
+	if err := m.applier.Apply(theme); err != nil {
+		return err
+	}
+	if m.applier.Capabilities().Imported {
+		m.previewed = theme.FullPath
+	}
+	return nil
+}
+
+// chosen records the use of the chosen theme, runs the [[hooks]] for it and
+// quits.
+func (m model) chosen(theme it.ThemeData) tea.Cmd {
+	return tea.Sequence(m.recordUse(theme.Name), m.runHooks(theme), tea.Quit)
+}
+
+// runHooks runs the [[hooks]] for theme, reporting their failures.
+func (m model) runHooks(theme it.ThemeData) tea.Cmd {
+	if len(m.hooks) == 0 || theme.FullPath == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := hooks.Run(context.Background(), m.hooks, hooks.NewTheme(theme)); err != nil {
+			return hooksFailedMsg{err}
+		}
+		return nil
+	}
+}
+
+func (m model) themeItems(themes []it.ThemeData) []list.Item {
+	var items []list.Item
+	for _, theme := range themes {
+		i := item{title: theme.Name, desc: theme.FullPath, theme: theme, active: theme.FullPath == m.currentTheme.FullPath}
+		i.mark = m.markIndex(theme) + 1
+		if m.store != nil {
+			i.favorite = m.store.IsFavorite(theme.Name)
+			i.tags = m.store.TagsOf(theme.Name)
+		}
+		items = append(items, i)
+	}
+	return items
 }
 
 func (m model) View() string {
 	if m.choice != "" {
-		return quitTextStyle.Render(fmt.Sprintf("Selected theme: %s", m.choice))
+		text := "Selected theme: " + m.choice
+		if !m.applier.Capabilities().Persistent {
+			text += " (until the window is closed)"
+		}
+		if m.hooksErr != nil {
+			text += "\n" + m.hooksErr.Error()
+		}
+		return quitTextStyle.Render(text)
 	}
 	if m.quitting {
-		// We first have to reset the config file
-		it.UpdateAlacrittyConfigFile(m.config, m.currentTheme)
 		return quitTextStyle.Render("Not making a selection? That’s cool.")
 	}
+	if m.err != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, renderErrorModal(m.err), renderStatusBar(m.status, m.statusIsErr))
+	}
+	if m.showHelp {
+		frameWidth, frameHeight := frameSize()
+		keys := clip(m.keys.renderHelp(), m.layout.width-frameWidth, m.layout.height-statusHeight-frameHeight)
+		help := frameStyle.Render(lipgloss.JoinVertical(lipgloss.Left, frameTitleStyle.Render("Keys"), keys))
+		return lipgloss.JoinVertical(lipgloss.Left, help, renderStatusBar(m.status, m.statusIsErr))
+	}
+	if m.comparing {
+		return lipgloss.JoinVertical(lipgloss.Left, m.renderComparison(), renderStatusBar(m.status, m.statusIsErr))
+	}
 
-	sampleText := m.sampleText //formatSampleText(m.sampleText)
+	sampleTitle, sampleText := m.sample()
 	sampleFrame := frameStyle.Render(
 		lipgloss.JoinVertical(
 			lipgloss.Left,
-			frameTitleStyle.Render("Sample"),
-			sampleText,
+			frameTitleStyle.Render(sampleTitle),
+			clip(m.scrolled(sampleText), m.layout.previewWidth, m.layout.previewHeight),
 		),
 	)
 
-	return lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		m.list.View(),
-		sampleFrame,
-	)
+	status := renderStatusBar(m.status, m.statusIsErr)
+	if m.tagging {
+		status = statusBarStyle.Render(m.tagInput.View())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.arrange(m.list.View(), sampleFrame), status)
 }
 
+// sample returns the title and the content of the sample panel.
+func (m model) sample() (string, string) {
+	theme, _ := m.highlightedTheme()
+	if m.showDetail {
+		return "Palette: " + theme.Name, renderDetailPanel(theme, m.detailSlot, m.keys)
+	}
+	if m.showAudit {
+		return "Readability", renderAuditPanel(theme)
+	}
+	// The scene is drawn with the highlighted theme's colors, so that it is
+	// right even before alacritty reloads its config
+	current := m.scenes[m.scene]
+	colors := theme.Palette
+	title := "Sample: " + current.name
+	if m.simulation != palette.NoDeficiency && colors != nil {
+		simulated := colors.Simulate(m.simulation)
+		colors = &simulated
+		title += " (" + m.simulation.String() + ")"
+	}
+	return title, renderScene(current, colors)
+}
 
+// InitializeMainModel builds the theme picker. Errors met while reading the
+// current theme or the theme list do not stop the program; they are shown in
+// the error modal together with the available recovery actions.
 func InitializeMainModel(config cf.Config) model {
+	var initErr error
 	currentTheme, err := it.GetCurrentTheme(config)
 	if err != nil {
-		fmt.Println("Error getting the current theme:", err)
-		os.Exit(1)
+		initErr = fmt.Errorf("error getting the current theme: %w", err)
+		currentTheme = &it.ThemeData{}
 	}
 	themedataList, err := it.GetThemeDataNames(config)
 	if err != nil {
-		fmt.Println("Error getting theme data:", err)
-		os.Exit(1)
+		initErr = errors.Join(initErr, fmt.Errorf("error getting theme data: %w", err))
 	}
 
-	var items []list.Item
-	for _, theme := range themedataList {
-		items = append(items, item{title: theme.Name, desc: theme.FullPath})
+	keys, err := newKeyMap(config.Keys)
+	if err != nil {
+		initErr = errors.Join(initErr, err)
+	}
+	a, err := applier.New(config, "")
+	if err != nil {
+		initErr = errors.Join(initErr, err)
+		a = applier.NewImport(config)
+	}
+	hs, err := hooks.FromConfig(config)
+	if err != nil {
+		initErr = errors.Join(initErr, err)
 	}
 
-	l := list.New(items, itemDelegate{}, listWidth, listHeight)
-	l.Title = "Select a Theme"
+	l := list.New(nil, itemDelegate{}, 0, 0)
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = titleStyle
 	l.Styles.PaginationStyle = paginationStyle
 	l.Styles.HelpStyle = helpStyle
-	sampleText :=
-		"|039| \033[39mDefault \033[m      |049| \033[49mDefault \033[m      |037| \033[37mLight gray \033[m     |047| \033[47mLight gray \033[m" + "\n" +
-			"|030| \033[30mBlack \033[m        |040| \033[40mBlack \033[m        |090| \033[90mDark gray \033[m      |100| \033[100mDark gray \033[m" + "\n" +
-			"|031| \033[31mRed \033[m          |041| \033[41mRed \033[m          |091| \033[91mLight red \033[m      |101| \033[101mLight red \033[m" + "\n" +
-			"|032| \033[32mGreen \033[m        |042| \033[42mGreen \033[m        |092| \033[92mLight green \033[m    |102| \033[102mLight green \033[m" + "\n" +
-			"|033| \033[33mYellow \033[m       |043| \033[43mYellow \033[m       |093| \033[93mLight yellow \033[m   |103| \033[103mLight yellow \033[m" + "\n" +
-			"|034| \033[34mBlue \033[m         |044| \033[44mBlue \033[m         |094| \033[94mLight blue \033[m     |104| \033[104mLight blue \033[m" + "\n" +
-			"|035| \033[35mMagenta \033[m      |045| \033[45mMagenta \033[m      |095| \033[95mLight magenta \033[m  |105| \033[105mLight magenta \033[m" + "\n" +
-			"|036| \033[36mCyan \033[m         |046| \033[46mCyan \033[m         |096| \033[96mLight cyan \033[m     |106| \033[106mLight cyan \033[m"
-
-	return model{
+	keys.applyToList(&l)
+	scenes := builtinScenes()
+	var scenesErr error
+	if dir := config.Preview.ScenesDirectory; dir != "" {
+		var userScenes []scene
+		userScenes, scenesErr = loadUserScenes(dir)
+		scenes = append(scenes, userScenes...)
+	}
+
+	m := model{
 		list:          l,
 		config:        config,
 		previousIndex: -1, // Initialize to an invalid index
 		currentTheme:  *currentTheme,
-		sampleText:    sampleText, // "Lorem ipsum dolor sit amet,\nconsectetur adipiscing elit.\nPhasellus imperdiet...",
+		previewed:     currentTheme.FullPath,
+		themes:        themedataList,
+		similarity:    it.NewSimilarityIndex(themedataList),
+		scenes:        scenes,
+		err:           initErr,
+		keys:          keys,
+		applier:       a,
+		hooks:         hs,
+	}
+	m.resize(defaultWidth, defaultHeight)
+	if scenesErr != nil {
+		m.status, m.statusIsErr = "Could not load the preview scenes: "+scenesErr.Error(), true
+	}
+	store, err := state.Load()
+	if err != nil {
+		m.status, m.statusIsErr = "Favorites and tags are not available: "+err.Error(), true
+	} else {
+		m.store = store
+	}
+	m.refreshItems()
+
+	// Live updates are a convenience, the picker works without them
+	w, err := watcher.New(it.ThemeDirectories(config), config.Paths.AlacrittyConfigPath)
+	if err != nil {
+		m.status, m.statusIsErr = "Not watching for changes: "+err.Error(), true
+	} else {
+		m.watcher = w
 	}
+	return m
 }
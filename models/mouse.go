@@ -0,0 +1,144 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doubleClickInterval is the longest time between the clicks of a double click.
+const doubleClickInterval = 400 * time.Millisecond
+
+// updateMouse handles mouse events: clicking a theme previews it, clicking
+// it again quickly chooses it, the wheel scrolls the list or the sample,
+// and clicking a color in the palette pane copies it.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.err != nil || m.tagging || m.showHelp || m.comparing {
+		return m, nil
+	}
+	row, inList := listRowAt(m.layout, m.listTop(), msg.X, msg.Y)
+	line, inSample := sampleLineAt(m.layout, msg.X, msg.Y)
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown:
+		up := msg.Button == tea.MouseButtonWheelUp
+		if inSample {
+			m.scrollSample(up)
+			return m, nil
+		}
+		if up {
+			m.list.CursorUp()
+		} else {
+			m.list.CursorDown()
+		}
+		return m, m.previewSelection()
+
+	case msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress:
+		return m, nil
+
+	case inList:
+		start, end := m.list.Paginator.GetSliceBounds(len(m.list.VisibleItems()))
+		index, ok := pageIndexAt(start, end, row)
+		if !ok {
+			return m, nil
+		}
+		now := time.Now()
+		double := isDoubleClick(m.lastClickIndex, m.lastClickAt, index, now)
+		m.lastClickIndex, m.lastClickAt = index, now
+		m.list.Select(index)
+		cmd := m.previewSelection()
+		if double {
+			theme, _ := m.highlightedTheme()
+			m.choice = theme.Name
+			m.closeWatcher()
+			return m, tea.Sequence(cmd, m.chosen(theme))
+		}
+		return m, cmd
+
+	case inSample && m.showDetail:
+		_, text := m.sample()
+		if slot, ok := slotAt(line, min(m.sampleScroll, m.maxSampleScroll(text))); ok {
+			m.detailSlot = slot
+			return m, m.copySlot()
+		}
+	}
+	return m, nil
+}
+
+// isDoubleClick tells whether a click on the list item index at now follows
+// the previous click on lastIndex at lastAt closely enough to choose it.
+func isDoubleClick(lastIndex int, lastAt time.Time, index int, now time.Time) bool {
+	return index == lastIndex && now.Sub(lastAt) < doubleClickInterval
+}
+
+// listTop returns the line of the list's first item, below its title bar.
+func (m model) listTop() int {
+	return m.list.Styles.TitleBar.GetVerticalFrameSize() + 1
+}
+
+// listRowAt returns the row of the list's items at the given cell of a
+// window laid out as l, whose first item is on line top.
+func listRowAt(l layout, top, x, y int) (int, bool) {
+	if x >= l.listWidth || y < top || y >= l.listHeight {
+		return 0, false
+	}
+	return y - top, true
+}
+
+// pageIndexAt returns the index of the item shown at row of a page holding
+// the items from start to end.
+func pageIndexAt(start, end, row int) (int, bool) {
+	if start+row >= end {
+		return 0, false
+	}
+	return start + row, true
+}
+
+// sampleLineAt returns the line of the sample's content at the given cell of
+// a window laid out as l.
+func sampleLineAt(l layout, x, y int) (int, bool) {
+	left := frameStyle.GetMarginLeft() + frameStyle.GetBorderLeftSize() + frameStyle.GetPaddingLeft()
+	top := frameStyle.GetMarginTop() + frameStyle.GetBorderTopSize() + frameStyle.GetPaddingTop() + 1
+	switch l.mode {
+	case layoutSideBySide:
+		left += l.listWidth
+	case layoutStacked:
+		top += l.listHeight
+	default:
+		return 0, false
+	}
+	if x < left || x >= left+l.previewWidth || y < top || y >= top+l.previewHeight {
+		return 0, false
+	}
+	return y - top, true
+}
+
+// slotAt returns the color slot of the palette pane shown at line of the
+// sample panel, scrolled by scroll lines.
+func slotAt(line, scroll int) (int, bool) {
+	slot := line + scroll
+	return slot, slot < slotCount
+}
+
+// scrollSample scrolls the sample panel by a line.
+func (m *model) scrollSample(up bool) {
+	if up {
+		m.sampleScroll = max(m.sampleScroll-1, 0)
+		return
+	}
+	_, text := m.sample()
+	m.sampleScroll = min(m.sampleScroll+1, m.maxSampleScroll(text))
+}
+
+// maxSampleScroll returns how far text can be scrolled in the sample panel.
+func (m model) maxSampleScroll(text string) int {
+	return max(strings.Count(text, "\n")+1-m.layout.previewHeight, 0)
+}
+
+// scrolled drops the lines of text scrolled out of the sample panel. It keeps
+// the panel full when the text got shorter since it was scrolled.
+func (m model) scrolled(text string) string {
+	offset := min(m.sampleScroll, m.maxSampleScroll(text))
+	return strings.Join(strings.Split(text, "\n")[offset:], "\n")
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestListRowAt tests the mapping of cells to rows of the list.
+func TestListRowAt(t *testing.T) {
+	l := computeLayout(defaultWidth, defaultHeight)
+	const top = 2
+	for _, c := range []struct {
+		x, y, row int
+		ok        bool
+	}{
+		{0, top, 0, true},
+		{l.listWidth - 1, top + 3, 3, true},
+		{0, l.listHeight - 1, l.listHeight - 1 - top, true},
+		{0, top - 1, 0, false},            // the title
+		{l.listWidth, top, 0, false},      // the sample
+		{0, l.listHeight, 0, false},       // the status bar
+		{10, defaultHeight + 5, 0, false}, // outside of the window
+	} {
+		row, ok := listRowAt(l, top, c.x, c.y)
+		assert.Equal(t, c.ok, ok, "%d,%d", c.x, c.y)
+		assert.Equal(t, c.row, row, "%d,%d", c.x, c.y)
+	}
+
+	// Stacked, the list takes the full width above the sample
+	l = computeLayout(60, 30)
+	row, ok := listRowAt(l, top, 59, top+1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, row)
+	_, ok = listRowAt(l, top, 0, l.listHeight)
+	assert.False(t, ok)
+}
+
+// TestPageIndexAt tests the mapping of rows to items of the current page.
+func TestPageIndexAt(t *testing.T) {
+	index, ok := pageIndexAt(20, 30, 3)
+	assert.True(t, ok)
+	assert.Equal(t, 23, index)
+	_, ok = pageIndexAt(20, 30, 10)
+	assert.False(t, ok)
+	// The last page is not full
+	_, ok = pageIndexAt(20, 22, 2)
+	assert.False(t, ok)
+}
+
+// TestSampleLineAt tests the mapping of cells to lines of the sample in the
+// three layout modes.
+func TestSampleLineAt(t *testing.T) {
+	left := frameStyle.GetMarginLeft() + frameStyle.GetBorderLeftSize() + frameStyle.GetPaddingLeft()
+	top := frameStyle.GetMarginTop() + frameStyle.GetBorderTopSize() + frameStyle.GetPaddingTop() + 1
+
+	l := computeLayout(defaultWidth, defaultHeight)
+	for _, c := range []struct {
+		x, y, line int
+		ok         bool
+	}{
+		{l.listWidth + left, top, 0, true},
+		{l.listWidth + left + l.previewWidth - 1, top + l.previewHeight - 1, l.previewHeight - 1, true},
+		{l.listWidth + left - 1, top, 0, false},               // the frame
+		{l.listWidth + left, top - 1, 0, false},               // the title
+		{l.listWidth + left + l.previewWidth, top, 0, false},  // the frame
+		{l.listWidth + left, top + l.previewHeight, 0, false}, // below the sample
+		{0, top, 0, false}, // the list
+	} {
+		line, ok := sampleLineAt(l, c.x, c.y)
+		assert.Equal(t, c.ok, ok, "%d,%d", c.x, c.y)
+		assert.Equal(t, c.line, line, "%d,%d", c.x, c.y)
+	}
+
+	l = computeLayout(60, 30)
+	line, ok := sampleLineAt(l, left, l.listHeight+top+2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, line)
+	_, ok = sampleLineAt(l, left, l.listHeight-1)
+	assert.False(t, ok)
+
+	_, ok = sampleLineAt(computeLayout(40, 10), left, top)
+	assert.False(t, ok, "no sample is shown")
+}
+
+// TestSlotAt tests the mapping of sample lines to the palette pane's slots.
+func TestSlotAt(t *testing.T) {
+	slot, ok := slotAt(0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 0, slot)
+	slot, ok = slotAt(3, 5)
+	assert.True(t, ok)
+	assert.Equal(t, 8, slot)
+	slot, ok = slotAt(slotCount-1, 0)
+	assert.True(t, ok)
+	assert.Equal(t, slotCount-1, slot)
+	// The help line below the slots
+	_, ok = slotAt(slotCount, 0)
+	assert.False(t, ok)
+}
+
+// TestIsDoubleClick tests that only quick clicks on the same item choose it.
+func TestIsDoubleClick(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.True(t, isDoubleClick(3, now.Add(-doubleClickInterval/2), 3, now))
+	assert.False(t, isDoubleClick(3, now.Add(-doubleClickInterval), 3, now))
+	assert.False(t, isDoubleClick(2, now.Add(-doubleClickInterval/2), 3, now))
+	// The first click of the picker
+	assert.False(t, isDoubleClick(0, time.Time{}, 0, now))
+}
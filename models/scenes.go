@@ -0,0 +1,235 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"goalacritty_themes/palette"
+)
+
+// ANSI escapes used to write the built-in scenes. They refer to the terminal
+// colors, which are replaced by the highlighted theme's colors when rendering.
+const (
+	rst     = "\033[m"
+	bold    = "\033[1m"
+	black   = "\033[30m"
+	red     = "\033[31m"
+	green   = "\033[32m"
+	yellow  = "\033[33m"
+	blue    = "\033[34m"
+	magenta = "\033[35m"
+	cyan    = "\033[36m"
+	white   = "\033[37m"
+	grey    = "\033[90m"
+	bgGreen = "\033[42m"
+	bgBlue  = "\033[44m"
+)
+
+// scene is a sample shown in the preview frame.
+type scene struct {
+	name string
+	// render returns the scene as text with ANSI escapes. Scenes which need
+	// more than the 16 colors, like the cursor and the selection, get the
+	// highlighted theme's palette, which may be nil.
+	render func(p *palette.Palette) string
+}
+
+// staticScene is a scene which only uses the 16 terminal colors.
+func staticScene(name, text string) scene {
+	return scene{name: name, render: func(*palette.Palette) string { return text }}
+}
+
+// builtinScenes returns the scenes which are always available.
+func builtinScenes() []scene {
+	return []scene{
+		staticScene("Colors", colorTableScene),
+		staticScene("Go", goScene),
+		staticScene("Python", pythonScene),
+		staticScene("Rust", rustScene),
+		staticScene("git diff", diffScene),
+		staticScene("ls --color", lsScene),
+		{name: "Shell", render: shellScene},
+		staticScene("htop", htopScene()),
+		staticScene("Gradients", gradientScene()),
+	}
+}
+
+// loadUserScenes reads every file in dir as a scene, named after the file.
+// The files may contain ANSI escapes, e.g. captured with `script`.
+func loadUserScenes(dir string) ([]scene, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var scenes []scene
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		scenes = append(scenes, staticScene(name, strings.TrimRight(string(content), "\n")))
+	}
+	sort.Slice(scenes, func(i, j int) bool { return scenes[i].name < scenes[j].name })
+	return scenes, nil
+}
+
+// renderScene renders s with the colors of p, or with the terminal's own
+// colors if p is nil.
+func renderScene(s scene, p *palette.Palette) string {
+	text := s.render(p)
+	if p == nil {
+		return text
+	}
+	return p.Recolor(text)
+}
+
+var colorTableScene = "|039| \033[39mDefault \033[m      |049| \033[49mDefault \033[m      |037| \033[37mLight gray \033[m     |047| \033[47mLight gray \033[m" + "\n" +
+	"|030| \033[30mBlack \033[m        |040| \033[40mBlack \033[m        |090| \033[90mDark gray \033[m      |100| \033[100mDark gray \033[m" + "\n" +
+	"|031| \033[31mRed \033[m          |041| \033[41mRed \033[m          |091| \033[91mLight red \033[m      |101| \033[101mLight red \033[m" + "\n" +
+	"|032| \033[32mGreen \033[m        |042| \033[42mGreen \033[m        |092| \033[92mLight green \033[m    |102| \033[102mLight green \033[m" + "\n" +
+	"|033| \033[33mYellow \033[m       |043| \033[43mYellow \033[m       |093| \033[93mLight yellow \033[m   |103| \033[103mLight yellow \033[m" + "\n" +
+	"|034| \033[34mBlue \033[m         |044| \033[44mBlue \033[m         |094| \033[94mLight blue \033[m     |104| \033[104mLight blue \033[m" + "\n" +
+	"|035| \033[35mMagenta \033[m      |045| \033[45mMagenta \033[m      |095| \033[95mLight magenta \033[m  |105| \033[105mLight magenta \033[m" + "\n" +
+	"|036| \033[36mCyan \033[m         |046| \033[46mCyan \033[m         |096| \033[96mLight cyan \033[m     |106| \033[106mLight cyan \033[m"
+
+var goScene = grey + "// Fib returns the n-th Fibonacci number." + rst + "\n" +
+	magenta + "func " + rst + blue + "Fib" + rst + "(n " + cyan + "int" + rst + ") " + cyan + "int" + rst + " {\n" +
+	"    " + magenta + "if" + rst + " n < " + yellow + "2" + rst + " {\n" +
+	"        " + magenta + "return" + rst + " n\n" +
+	"    }\n" +
+	"    a, b := " + yellow + "0" + rst + ", " + yellow + "1" + rst + "\n" +
+	"    " + magenta + "for" + rst + " i := " + yellow + "1" + rst + "; i < n; i++ {\n" +
+	"        a, b = b, a+b\n" +
+	"    }\n" +
+	"    fmt." + blue + "Println" + rst + "(" + green + "\"done\"" + rst + ")\n" +
+	"    " + magenta + "return" + rst + " b\n" +
+	"}"
+
+var pythonScene = magenta + "from" + rst + " dataclasses " + magenta + "import" + rst + " dataclass\n\n" +
+	yellow + "@dataclass" + rst + "\n" +
+	magenta + "class " + rst + cyan + "Theme" + rst + ":\n" +
+	"    name: " + cyan + "str" + rst + "\n" +
+	"    dark: " + cyan + "bool" + rst + " = " + yellow + "True" + rst + "\n\n" +
+	"    " + magenta + "def " + rst + blue + "describe" + rst + "(" + red + "self" + rst + ") -> " + cyan + "str" + rst + ":\n" +
+	"        " + grey + "# f-strings and escapes" + rst + "\n" +
+	"        " + magenta + "return" + rst + " " + green + "f\"{" + rst + red + "self" + rst + ".name" + green + "} is \\t" + rst + yellow + "{'dark' if self.dark else 'light'}" + rst + green + "\"" + rst
+
+var rustScene = magenta + "use" + rst + " std::collections::" + cyan + "HashMap" + rst + ";\n\n" +
+	yellow + "#[derive(Debug)]" + rst + "\n" +
+	magenta + "enum " + rst + cyan + "Event" + rst + " { " + cyan + "Switch" + rst + "(" + cyan + "String" + rst + "), " + cyan + "Reset" + rst + " }\n\n" +
+	magenta + "fn " + rst + blue + "main" + rst + "() {\n" +
+	"    " + magenta + "let mut" + rst + " seen: " + cyan + "HashMap" + rst + "<&" + red + "'static" + rst + " " + cyan + "str" + rst + ", " + cyan + "u32" + rst + "> = " + cyan + "HashMap" + rst + "::" + blue + "new" + rst + "();\n" +
+	"    *seen." + blue + "entry" + rst + "(" + green + "\"nord\"" + rst + ")." + blue + "or_insert" + rst + "(" + yellow + "0" + rst + ") += " + yellow + "1" + rst + ";\n" +
+	"    " + blue + "println!" + rst + "(" + green + "\"{:?}\"" + rst + ", " + cyan + "Event" + rst + "::" + cyan + "Reset" + rst + ");\n" +
+	"}"
+
+var diffScene = bold + "diff --git a/models/model.go b/models/model.go" + rst + "\n" +
+	bold + "index 3f2a1c4..8e9d0b7 100644" + rst + "\n" +
+	bold + "--- a/models/model.go" + rst + "\n" +
+	bold + "+++ b/models/model.go" + rst + "\n" +
+	cyan + "@@ -98,7 +98,9 @@" + rst + " func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {\n" +
+	"     case tea.WindowSizeMsg:\n" +
+	red + "-        m.list.SetWidth(msg.Width - sampleTextWidth - 4)" + rst + "\n" +
+	red + "-        return m, nil" + rst + "\n" +
+	green + "+        m.width, m.height = msg.Width, msg.Height" + rst + "\n" +
+	green + "+        m.layout()" + rst + "\n" +
+	green + "+        return m, nil" + rst + "\n" +
+	"     case statusMsg:"
+
+var lsScene = "total 48\n" +
+	"drwxr-xr-x  5 iwashis staff  160 Oct  3 09:12 " + bold + blue + "config" + rst + "\n" +
+	"drwxr-xr-x 12 iwashis staff  384 Oct  3 09:12 " + bold + blue + "models" + rst + "\n" +
+	"-rwxr-xr-x  1 iwashis staff 8.1M Oct  3 09:14 " + bold + green + "goalacritty" + rst + "\n" +
+	"-rw-r--r--  1 iwashis staff 1.9K Oct  3 09:12 README.md\n" +
+	"lrwxr-xr-x  1 iwashis staff   24 Oct  3 09:12 " + bold + cyan + "themes" + rst + " -> ~/.config/alacritty/themes\n" +
+	"-rw-r--r--  1 iwashis staff  12K Oct  2 18:40 " + bold + red + "backup.tar.gz" + rst + "\n" +
+	"-rw-r--r--  1 iwashis staff 140K Oct  1 11:02 " + bold + magenta + "selection_menu.png" + rst + "\n" +
+	"prw-r--r--  1 iwashis staff    0 Oct  3 09:13 " + yellow + "events.fifo" + rst + "\n" +
+	"drwxrwxrwt  2 iwashis staff   64 Oct  3 09:13 " + "\033[30;42m" + "shared" + rst
+
+// shellScene shows a prompt with a selection and the cursor, drawn with the
+// palette's selection and cursor colors when it defines them.
+func shellScene(p *palette.Palette) string {
+	selection := "\033[7m"
+	cursor := "\033[7m"
+	if p != nil && p.SelectionBackground != nil {
+		selection = trueColor(48, *p.SelectionBackground)
+		if p.SelectionText != nil {
+			selection += trueColor(38, *p.SelectionText)
+		}
+	}
+	if p != nil && p.Cursor != nil {
+		cursor = trueColor(48, *p.Cursor)
+		if p.CursorText != nil {
+			cursor += trueColor(38, *p.CursorText)
+		}
+	}
+	prompt := bold + green + "iwashis@mbp" + rst + ":" + bold + blue + "~/src/goalacritty" + rst + " " + magenta + "(main *)" + rst + " $ "
+	return prompt + "git log --oneline -3\n" +
+		yellow + "a7a42bd" + rst + " (" + bold + cyan + "HEAD -> " + green + "main" + rst + ") " + selection + "baseline" + rst + "\n" +
+		yellow + "61c2e0f" + rst + " Add theme preview\n" +
+		yellow + "0b9e3d4" + rst + " Initial commit\n" +
+		prompt + "go test ./...\n" +
+		"ok  \tgoalacritty_themes/config\t0.004s\n" +
+		red + "FAIL" + rst + "\tgoalacritty_themes/models\t0.012s\n" +
+		prompt + "echo " + cursor + "$" + rst + "THEME"
+}
+
+func trueColor(layer int, c palette.Color) string {
+	return fmt.Sprintf("\033[%d;2;%d;%d;%dm", layer, c.R, c.G, c.B)
+}
+
+// htopScene imitates the header and process list of htop.
+func htopScene() string {
+	bar := func(label string, parts ...string) string {
+		return "  " + cyan + label + rst + white + "[" + rst + strings.Join(parts, "") + white + "]" + rst
+	}
+	return bar("1", green+"||||||||"+red+"||"+rst+"          ", grey+" 37.5%"+rst) + "   " + bar("Mem", green+"|||||||"+blue+"||"+yellow+"|||"+rst+"     ", grey+"5.2G/16G"+rst) + "\n" +
+		bar("2", green+"|||"+red+"|"+rst+"                ", grey+" 12.1%"+rst) + "   " + bar("Swp", red+"|"+rst+"                ", grey+" 128M/2G"+rst) + "\n" +
+		"  " + cyan + "Tasks: " + rst + bold + "212" + rst + ", " + green + "1 running" + rst + "   " + cyan + "Load average: " + rst + bold + "1.42 " + rst + "1.10 0.98\n\n" +
+		bgGreen + black + "    PID USER      PRI  NI  VIRT   RES S CPU% MEM% Command          " + rst + "\n" +
+		"   4242 iwashis    20   0  1.2G  310M " + green + "R" + rst + " 24.0  1.9 " + bold + "alacritty" + rst + "\n" +
+		bgBlue + black + "   4310 iwashis    20   0  812M   96M S  6.5  0.6 goalacritty      " + rst + "\n" +
+		"    913 root       20   0  402M   41M S  0.7  0.3 " + grey + "/usr/sbin/" + rst + "sshd\n" +
+		"   5120 iwashis    " + red + "39  19" + rst + "  2.0G  1.1G D  0.0  7.0 " + bold + "nvim" + rst
+}
+
+// gradientScene shows the 256-color palette and 24-bit gradients, which do
+// not depend on the theme, next to the theme's 16 colors.
+func gradientScene() string {
+	var b strings.Builder
+	for i := 0; i < 16; i++ {
+		fmt.Fprintf(&b, "\033[48;5;%dm  ", i)
+	}
+	b.WriteString(rst + "\n")
+	for row := 0; row < 6; row++ {
+		for col := 0; col < 36; col++ {
+			fmt.Fprintf(&b, "\033[48;5;%dm ", 16+row*36+col)
+		}
+		b.WriteString(rst + "\n")
+	}
+	for i := 232; i < 256; i++ {
+		fmt.Fprintf(&b, "\033[48;5;%dm ", i)
+	}
+	b.WriteString(rst + "\n")
+	for _, channel := range []func(v int) (int, int, int){
+		func(v int) (int, int, int) { return v, 0, 0 },
+		func(v int) (int, int, int) { return 0, v, 0 },
+		func(v int) (int, int, int) { return 0, 0, v },
+		func(v int) (int, int, int) { return v, v, v },
+	} {
+		for i := 0; i < 48; i++ {
+			r, g, bl := channel(i * 255 / 47)
+			fmt.Fprintf(&b, "\033[48;2;%d;%d;%dm ", r, g, bl)
+		}
+		b.WriteString(rst + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
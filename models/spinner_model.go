@@ -7,7 +7,6 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	cf "goalacritty_themes/config"
 	it "goalacritty_themes/theme_tools"
-	"os"
 )
 
 var (
@@ -15,59 +14,85 @@ var (
 	spinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 )
 
+// installDoneMsg is sent once the theme repository has been cloned and the
+// alacritty config initialised, or once either step failed.
+type installDoneMsg struct{ err error }
+
 // spinnerModel and its Init, Update and View functions
 // for cloning theme repo (if missing)
 type spinnerModel struct {
 	spinner spinner.Model
 	config  cf.Config
-	done    chan struct{}
+	err     error
 }
 
-// TODO: error handling
 func (m spinnerModel) Init() tea.Cmd {
-	go func() {
-		err := it.InstallThemes(m.config)
-		if err != nil {
-			fmt.Println("Error installing themes:", err)
-			os.Exit(1)
+	return tea.Batch(m.spinner.Tick, installThemes(m.config, it.InstallThemes))
+}
+
+// installThemes runs install (a fresh clone or a reinstall) and points the
+// alacritty config at the first theme.
+func installThemes(config cf.Config, install func(cf.Config) error) tea.Cmd {
+	return func() tea.Msg {
+		if err := install(config); err != nil {
+			return installDoneMsg{fmt.Errorf("error installing themes: %w", err)}
 		}
-		themes, err := it.GetThemeDataNames(m.config)
+		themes, err := it.GetThemeDataNames(config)
 		if err != nil {
-			fmt.Println("Error retrieving theme names:", err)
-			os.Exit(1)
+			return installDoneMsg{fmt.Errorf("error retrieving theme names: %w", err)}
 		}
-		firstTheme := themes[0]
-		err = it.InitAlacrittyConfig(m.config, firstTheme)
-		if err != nil {
-			fmt.Println("Error retrieving theme names:", err)
-			os.Exit(1)
+		if err := it.InitAlacrittyConfig(config, themes[0]); err != nil {
+			return installDoneMsg{fmt.Errorf("error initialising the alacritty config: %w", err)}
 		}
-		close(m.done)
-	}()
-	return m.spinner.Tick
+		return installDoneMsg{}
+	}
 }
 
 func (m spinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	select {
-	case <-m.done:
+	switch msg := msg.(type) {
+	case installDoneMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
 		mainModel := InitializeMainModel(m.config)
-		return mainModel, nil
-	default:
-		switch msg := msg.(type) {
-		case tea.KeyMsg:
-			if msg.String() == "ctrl+c" {
+		return mainModel, mainModel.Init()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "q":
+			if m.err != nil {
 				return m, tea.Quit
 			}
-		case spinner.TickMsg:
-			var cmd tea.Cmd
-			m.spinner, cmd = m.spinner.Update(msg)
-			return m, cmd
+		case "r":
+			// A failed clone usually leaves a half-populated directory
+			// behind, so retrying means starting from scratch.
+			if m.err != nil {
+				m.err = nil
+				return m, tea.Batch(m.spinner.Tick, installThemes(m.config, it.ReinstallThemes))
+			}
 		}
+	case spinner.TickMsg:
+		if m.err != nil {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
 	}
 	return m, nil
 }
 
 func (m spinnerModel) View() string {
+	if m.err != nil {
+		return modalStyle.Render(
+			modalTitleStyle.Render("Installing themes failed") + "\n\n" +
+				m.err.Error() + "\n\n" +
+				modalKeyStyle.Render("r") + "  retry from scratch\n" +
+				modalKeyStyle.Render("q") + "  quit",
+		)
+	}
 	return fmt.Sprintf("\n\n   %s Installing themes...", m.spinner.View())
 }
 
@@ -79,7 +104,6 @@ func InitializeSpinnerModel(config cf.Config) spinnerModel {
 	m := spinnerModel{
 		spinner: s,
 		config:  config,
-		done:    make(chan struct{}),
 	}
 	return m
 }
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+var (
+	statusBarStyle  = lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("241"))
+	statusErrStyle  = lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("203"))
+	modalStyle      = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2).Margin(1, 4).BorderForeground(lipgloss.Color("203"))
+	modalTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("203"))
+	modalKeyStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("150"))
+)
+
+// Messages used by the models to report progress and failures. Long running
+// work is done in tea.Cmds which send one of these back to Update, so nothing
+// in this package prints to the terminal or exits the process on its own.
+type (
+	// statusMsg is a short informational line shown in the status bar.
+	statusMsg string
+	// errMsg carries an error that should be shown in the error modal.
+	errMsg struct{ err error }
+	// hooksFailedMsg reports the hooks which failed after a theme was chosen.
+	hooksFailedMsg struct{ err error }
+	// themesLoadedMsg is sent once the theme list has been (re)read.
+	themesLoadedMsg struct {
+		themes []it.ThemeData
+		err    error
+	}
+)
+
+func reportError(err error) tea.Cmd {
+	return func() tea.Msg { return errMsg{err} }
+}
+
+func reportStatus(format string, a ...any) tea.Cmd {
+	return func() tea.Msg { return statusMsg(fmt.Sprintf(format, a...)) }
+}
+
+// loadThemes reads the theme list in the background.
+func loadThemes(config cf.Config) tea.Cmd {
+	return func() tea.Msg {
+		themes, err := it.GetThemeDataNames(config)
+		return themesLoadedMsg{themes, err}
+	}
+}
+
+// recovery is an action offered to the user in the error modal.
+type recovery struct {
+	key   string
+	label string
+	run   func(config cf.Config, theme it.ThemeData) tea.Cmd
+}
+
+// recoveriesFor returns the actions that may fix err.
+func recoveriesFor(err error) []recovery {
+	switch {
+	case errors.Is(err, it.ErrRepoCorrupt):
+		return []recovery{{
+			key:   "r",
+			label: "reinstall the themes repository",
+			run: func(config cf.Config, _ it.ThemeData) tea.Cmd {
+				return func() tea.Msg {
+					if err := it.ReinstallThemes(config); err != nil {
+						return errMsg{err}
+					}
+					return loadThemes(config)()
+				}
+			},
+		}}
+	case errors.Is(err, it.ErrConfigNotFound):
+		return []recovery{{
+			key:   "c",
+			label: "create a new alacritty config using the highlighted theme",
+			run: func(config cf.Config, theme it.ThemeData) tea.Cmd {
+				return func() tea.Msg {
+					if err := it.InitAlacrittyConfig(config, theme); err != nil {
+						return errMsg{err}
+					}
+					return statusMsg("Created " + config.Paths.AlacrittyConfigPath)
+				}
+			},
+		}}
+	case errors.Is(err, it.ErrThemeMissing):
+		return []recovery{{
+			key:   "r",
+			label: "reload the theme list",
+			run: func(config cf.Config, _ it.ThemeData) tea.Cmd {
+				return loadThemes(config)
+			},
+		}}
+	}
+	return nil
+}
+
+// renderStatusBar renders the status line below the list.
+func renderStatusBar(status string, isErr bool) string {
+	if status == "" {
+		return ""
+	}
+	if isErr {
+		return statusErrStyle.Render(status)
+	}
+	return statusBarStyle.Render(status)
+}
+
+// renderErrorModal renders err together with the recovery actions available for it.
+func renderErrorModal(err error) string {
+	var b strings.Builder
+	b.WriteString(modalTitleStyle.Render("Something went wrong"))
+	b.WriteString("\n\n")
+	b.WriteString(err.Error())
+	b.WriteString("\n\n")
+	for _, r := range recoveriesFor(err) {
+		b.WriteString(modalKeyStyle.Render(r.key) + "  " + r.label + "\n")
+	}
+	b.WriteString(modalKeyStyle.Render("esc") + "  dismiss\n")
+	b.WriteString(modalKeyStyle.Render("q") + "  quit")
+	return modalStyle.Render(b.String())
+}
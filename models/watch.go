@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"goalacritty_themes/applier"
+	it "goalacritty_themes/theme_tools"
+	"goalacritty_themes/watcher"
+)
+
+// watchMsg carries a batch of changes to the theme files or alacritty config
+// made outside of the picker.
+type watchMsg []watcher.Event
+
+// waitForChanges waits for the next batch of changes reported by w.
+func waitForChanges(w *watcher.Watcher) tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case events := <-w.Events():
+			return watchMsg(events)
+		case err := <-w.Errors():
+			return errMsg{fmt.Errorf("error watching for changes: %w", err)}
+		}
+	}
+}
+
+// handleChanges reloads the theme list when theme files changed, and picks up
+// a theme chosen outside of the picker when the alacritty config changed.
+func (m model) handleChanges(events watchMsg) (model, tea.Cmd) {
+	cmds := []tea.Cmd{waitForChanges(m.watcher)}
+	reload := false
+	for _, ev := range events {
+		if ev.Kind != watcher.ConfigChanged {
+			reload = true
+			continue
+		}
+		current, err := it.GetCurrentTheme(m.config)
+		if err != nil {
+			cmds = append(cmds, reportError(err))
+			continue
+		}
+		// Our own previews change the config too
+		if current.FullPath != "" && current.FullPath != m.previewed {
+			m.currentTheme = *current
+			m.previewed = current.FullPath
+			if m.applier.Capabilities().Imported {
+				// Quitting restores the new theme rather than the one
+				// imported when the picker started
+				m.applier = applier.NewImport(m.config)
+			}
+			cmds = append(cmds, m.refreshItems(), reportStatus("alacritty config changed, the active theme is now %s", current.Name))
+		}
+	}
+	if reload {
+		cmds = append(cmds, loadThemes(m.config))
+	}
+	return m, tea.Batch(cmds...)
+}
@@ -0,0 +1,67 @@
+// Package osc recolors a terminal with OSC escape sequences. Unlike editing
+// the alacritty config, this only affects the terminal the sequences are
+// written to, and only until it is reset or closed.
+package osc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"goalacritty_themes/palette"
+)
+
+// Palette returns the sequences setting the terminal's colors to p: the
+// 16 terminal colors, the foreground, background and cursor, and the
+// selection colors if p defines them.
+func Palette(p palette.Palette) string {
+	var b strings.Builder
+	for i := 0; i < 16; i++ {
+		fmt.Fprintf(&b, "\x1b]4;%d;%s\a", i, rgb(p.ANSI(i)))
+	}
+	set := func(code int, c *palette.Color) {
+		if c != nil {
+			fmt.Fprintf(&b, "\x1b]%d;%s\a", code, rgb(*c))
+		}
+	}
+	set(10, &p.Foreground)
+	set(11, &p.Background)
+	set(12, p.Cursor)
+	set(17, p.SelectionBackground)
+	set(19, p.SelectionText)
+	return b.String()
+}
+
+// Reset returns the sequences restoring the colors of the terminal's
+// configuration.
+func Reset() string {
+	var b strings.Builder
+	for _, code := range []int{104, 110, 111, 112, 117, 119} {
+		fmt.Fprintf(&b, "\x1b]%d\a", code)
+	}
+	return b.String()
+}
+
+// rgb formats c the way xterm's color sequences expect.
+func rgb(c palette.Color) string {
+	return fmt.Sprintf("rgb:%02x/%02x/%02x", c.R, c.G, c.B)
+}
+
+// Wrap passes seq through a terminal multiplexer to the terminal running it,
+// tmux and screen would otherwise apply or drop it themselves. Screen limits
+// the length of what it passes through, so each sequence is wrapped alone.
+func Wrap(seq string) string {
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		var b strings.Builder
+		for _, s := range strings.SplitAfter(seq, "\a") {
+			if s != "" {
+				b.WriteString("\x1bP" + s + "\x1b\\")
+			}
+		}
+		return b.String()
+	}
+	return seq
+}
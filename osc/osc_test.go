@@ -0,0 +1,58 @@
+package osc
+
+import (
+	"strings"
+	"testing"
+
+	"goalacritty_themes/palette"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const theme = `
+[colors.primary]
+background = "#1a1b26"
+foreground = "#c0caf5"
+[colors.cursor]
+cursor = "#ffffff"
+[colors.normal]
+black = "#000000"
+red = "#ff0000"
+green = "#00ff00"
+yellow = "#ffff00"
+blue = "#0000ff"
+magenta = "#ff00ff"
+cyan = "#00ffff"
+white = "#ffffff"
+`
+
+// TestPalette tests the sequences setting a palette
+func TestPalette(t *testing.T) {
+	p, err := palette.Parse([]byte(theme))
+	assert.NoError(t, err)
+	seq := Palette(*p)
+
+	assert.True(t, strings.HasPrefix(seq, "\x1b]4;0;rgb:00/00/00\a\x1b]4;1;rgb:ff/00/00\a"))
+	assert.Contains(t, seq, "\x1b]10;rgb:c0/ca/f5\a")
+	assert.Contains(t, seq, "\x1b]11;rgb:1a/1b/26\a")
+	assert.Contains(t, seq, "\x1b]12;rgb:ff/ff/ff\a")
+	// The theme has no selection colors, the terminal keeps its own
+	assert.NotContains(t, seq, "\x1b]17;")
+	assert.NotContains(t, seq, "\x1b]19;")
+	assert.Equal(t, 19, strings.Count(seq, "\a"))
+}
+
+// TestWrap tests passing sequences through tmux and screen
+func TestWrap(t *testing.T) {
+	seq := "\x1b]10;rgb:00/00/00\a\x1b]11;rgb:ff/ff/ff\a"
+
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "alacritty")
+	assert.Equal(t, seq, Wrap(seq))
+
+	t.Setenv("TERM", "screen-256color")
+	assert.Equal(t, "\x1bP\x1b]10;rgb:00/00/00\a\x1b\\\x1bP\x1b]11;rgb:ff/ff/ff\a\x1b\\", Wrap(seq))
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]10;rgb:00/00/00\a\x1b\x1b]11;rgb:ff/ff/ff\a\x1b\\", Wrap(seq))
+}
@@ -0,0 +1,99 @@
+package palette
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Recolor rewrites the SGR sequences of s so that it renders with this
+// palette instead of the terminal's own colors: the 16 ANSI colors (also as
+// 256-color indices) and the default foreground and background become 24-bit
+// colors. Other sequences, like erasing the line, are left untouched.
+func (p Palette) Recolor(s string) string {
+	defaults := p.sgr("38;2;%d;%d;%d", p.Foreground) + ";" + p.sgr("48;2;%d;%d;%d", p.Background)
+	var b strings.Builder
+	b.WriteString("\x1b[" + defaults + "m")
+	for {
+		start := strings.Index(s, "\x1b[")
+		newline := strings.IndexByte(s, '\n')
+		if newline >= 0 && (start < 0 || newline < start) {
+			// Reset at the end of each line so that the colors do not leak
+			// into whatever is drawn around the text
+			b.WriteString(s[:newline])
+			b.WriteString("\x1b[0m\n\x1b[" + defaults + "m")
+			s = s[newline+1:]
+			continue
+		}
+		if start < 0 {
+			break
+		}
+		// A CSI sequence ends with its first byte in 0x40-0x7e; only those
+		// ending in m set colors
+		end := strings.IndexFunc(s[start+2:], func(r rune) bool { return r >= 0x40 && r <= 0x7e })
+		if end < 0 {
+			break
+		}
+		end += start + 2
+		b.WriteString(s[:start])
+		if s[end] == 'm' {
+			b.WriteString("\x1b[" + p.recolorParams(s[start+2:end], defaults) + "m")
+		} else {
+			b.WriteString(s[start : end+1])
+		}
+		s = s[end+1:]
+	}
+	b.WriteString(s)
+	b.WriteString("\x1b[0m")
+	return b.String()
+}
+
+func (p Palette) sgr(format string, c Color) string {
+	return fmt.Sprintf(format, c.R, c.G, c.B)
+}
+
+// recolorParams translates the parameters of a single SGR sequence.
+func (p Palette) recolorParams(params, defaults string) string {
+	codes := strings.Split(params, ";")
+	var out []string
+	for i := 0; i < len(codes); i++ {
+		n, err := strconv.Atoi(codes[i])
+		if codes[i] == "" {
+			n, err = 0, nil
+		}
+		if err != nil {
+			out = append(out, codes[i])
+			continue
+		}
+		switch {
+		case n == 0:
+			out = append(out, "0", defaults)
+		case n >= 30 && n <= 37:
+			out = append(out, p.sgr("38;2;%d;%d;%d", p.Normal[n-30]))
+		case n >= 90 && n <= 97:
+			out = append(out, p.sgr("38;2;%d;%d;%d", p.Bright[n-90]))
+		case n >= 40 && n <= 47:
+			out = append(out, p.sgr("48;2;%d;%d;%d", p.Normal[n-40]))
+		case n >= 100 && n <= 107:
+			out = append(out, p.sgr("48;2;%d;%d;%d", p.Bright[n-100]))
+		case n == 39:
+			out = append(out, p.sgr("38;2;%d;%d;%d", p.Foreground))
+		case n == 49:
+			out = append(out, p.sgr("48;2;%d;%d;%d", p.Background))
+		case (n == 38 || n == 48) && i+2 < len(codes) && codes[i+1] == "5":
+			index, err := strconv.Atoi(codes[i+2])
+			if err == nil && index >= 0 && index < 16 {
+				out = append(out, p.sgr(strconv.Itoa(n)+";2;%d;%d;%d", p.ANSI(index)))
+			} else {
+				out = append(out, codes[i:i+3]...)
+			}
+			i += 2
+		case (n == 38 || n == 48) && i+4 < len(codes) && codes[i+1] == "2":
+			out = append(out, codes[i:i+5]...)
+			i += 4
+		default:
+			out = append(out, codes[i])
+		}
+	}
+	return strings.Join(out, ";")
+}
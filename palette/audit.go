@@ -0,0 +1,104 @@
+package palette
+
+import "math"
+
+// Minimum contrast for a pair to pass the audit. Body text (foreground and
+// selection) must reach WCAG AA for normal text; ANSI colors and the cursor
+// must reach the 3:1 required for large text and UI components.
+const (
+	textMinContrast = 4.5
+	uiMinContrast   = 3
+	textMinLc       = 60
+	uiMinLc         = 45
+)
+
+// PairResult is the contrast of one text/background pair of a palette.
+type PairResult struct {
+	Name       string
+	Text       Color
+	Background Color
+	WCAG       float64 // contrast ratio, 1 to 21
+	APCA       float64 // lightness contrast, signed
+	MinWCAG    float64
+	MinAPCA    float64
+	Weight     float64
+}
+
+// Pass reports whether the pair meets both the WCAG and APCA minimums.
+func (r PairResult) Pass() bool {
+	return r.WCAG >= r.MinWCAG && math.Abs(r.APCA) >= r.MinAPCA
+}
+
+// AuditResult is the readability audit of a palette.
+type AuditResult struct {
+	Pairs []PairResult
+	// Score goes from 0 to 100, 100 meaning that every pair passes
+	Score float64
+	// CVD is the smallest distance between red, green and yellow for each
+	// simulated color vision deficiency, see ConfusionDistance
+	CVD map[Deficiency]float64
+}
+
+// MinConfusionDistance is the ConfusionDistance below which red, green and
+// yellow are considered hard to tell apart.
+const MinConfusionDistance = 10
+
+// Failing returns the pairs which do not pass.
+func (a AuditResult) Failing() []PairResult {
+	var failing []PairResult
+	for _, r := range a.Pairs {
+		if !r.Pass() {
+			failing = append(failing, r)
+		}
+	}
+	return failing
+}
+
+func newPair(name string, text, background Color, minWCAG, minAPCA, weight float64) PairResult {
+	return PairResult{
+		Name:       name,
+		Text:       text,
+		Background: background,
+		WCAG:       ContrastRatio(text, background),
+		APCA:       APCA(text, background),
+		MinWCAG:    minWCAG,
+		MinAPCA:    minAPCA,
+		Weight:     weight,
+	}
+}
+
+// Audit computes the contrast of the foreground, every ANSI color, the
+// selection and the cursor against their backgrounds.
+func (p Palette) Audit() AuditResult {
+	pairs := []PairResult{newPair("foreground", p.Foreground, p.Background, textMinContrast, textMinLc, 4)}
+	for i, name := range ANSINames {
+		pairs = append(pairs, newPair(name, p.Normal[i], p.Background, uiMinContrast, uiMinLc, 1))
+	}
+	for i, name := range ANSINames {
+		pairs = append(pairs, newPair("bright "+name, p.Bright[i], p.Background, uiMinContrast, uiMinLc, 1))
+	}
+	if p.SelectionBackground != nil {
+		// Without an explicit selection text color the cell foreground is used
+		text := p.Foreground
+		if p.SelectionText != nil {
+			text = *p.SelectionText
+		}
+		pairs = append(pairs, newPair("selection", text, *p.SelectionBackground, textMinContrast, textMinLc, 2))
+	}
+	if p.Cursor != nil {
+		pairs = append(pairs, newPair("cursor", *p.Cursor, p.Background, uiMinContrast, uiMinLc, 1))
+	}
+
+	// Each pair contributes how close it gets to its minimum, capped at 1
+	var score, weights float64
+	for _, r := range pairs {
+		reached := math.Min(r.WCAG/r.MinWCAG, math.Abs(r.APCA)/r.MinAPCA)
+		score += r.Weight * math.Min(reached, 1)
+		weights += r.Weight
+	}
+	cvd := map[Deficiency]float64{}
+	for _, d := range Deficiencies {
+		cvd[d] = p.ConfusionDistance(d)
+	}
+	return AuditResult{Pairs: pairs, Score: 100 * score / weights, CVD: cvd}
+}
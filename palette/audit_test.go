@@ -0,0 +1,57 @@
+package palette
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestContrastRatio tests the WCAG contrast ratio against reference values.
+func TestContrastRatio(t *testing.T) {
+	black, white := Color{}, Color{R: 255, G: 255, B: 255}
+	assert.InDelta(t, 21, ContrastRatio(black, white), 0.001)
+	assert.InDelta(t, 21, ContrastRatio(white, black), 0.001, "Order should not matter")
+	assert.InDelta(t, 1, ContrastRatio(white, white), 0.001)
+
+	grey, _ := ParseColor("#777777")
+	assert.InDelta(t, 4.48, ContrastRatio(grey, white), 0.01)
+}
+
+// TestAPCA tests the APCA lightness contrast against reference values.
+func TestAPCA(t *testing.T) {
+	black, white := Color{}, Color{R: 255, G: 255, B: 255}
+	assert.InDelta(t, 106.04, APCA(black, white), 0.1)
+	assert.InDelta(t, -107.88, APCA(white, black), 0.1)
+	assert.Equal(t, 0.0, APCA(white, white))
+
+	text, _ := ParseColor("#888888")
+	bg, _ := ParseColor("#ffffff")
+	assert.InDelta(t, 63.06, APCA(text, bg), 0.1)
+}
+
+// TestAudit tests that low contrast pairs are flagged and lower the score.
+func TestAudit(t *testing.T) {
+	p, err := Parse([]byte(mockTheme))
+	assert.NoError(t, err)
+
+	audit := p.Audit()
+	assert.Len(t, audit.Pairs, 1+16+2, "Expected foreground, 16 ANSI colors, selection and cursor")
+	assert.Equal(t, "foreground", audit.Pairs[0].Name)
+	assert.True(t, audit.Pairs[0].Pass())
+
+	var failing []string
+	for _, r := range audit.Failing() {
+		failing = append(failing, r.Name)
+	}
+	assert.Contains(t, failing, "black", "Black on the dracula background should be flagged")
+	assert.NotContains(t, failing, "foreground")
+	assert.Greater(t, audit.Score, 50.0)
+	assert.Less(t, audit.Score, 100.0)
+
+	// Make the foreground nearly invisible
+	p.Foreground = p.Background
+	p.Foreground.R += 8
+	worse := p.Audit()
+	assert.False(t, worse.Pairs[0].Pass())
+	assert.Less(t, worse.Score, audit.Score)
+}
@@ -0,0 +1,49 @@
+package palette
+
+import "math"
+
+// Class tells whether a theme is dark or light.
+type Class int
+
+const (
+	Unknown Class = iota
+	Dark
+	Light
+)
+
+func (c Class) String() string {
+	switch c {
+	case Dark:
+		return "dark"
+	case Light:
+		return "light"
+	}
+	return "unknown"
+}
+
+// ParseClass parses "dark" or "light".
+func ParseClass(s string) (Class, bool) {
+	switch s {
+	case "dark":
+		return Dark, true
+	case "light":
+		return Light, true
+	}
+	return Unknown, false
+}
+
+// lightnessThreshold is the perceived lightness (L*) separating dark from
+// light backgrounds: mid grey.
+const lightnessThreshold = 50
+
+// Classify classifies the palette by the perceived lightness of its
+// background. The confidence goes from 0 for a mid grey background to 1 for
+// pure black or white.
+func (p Palette) Classify() (Class, float64) {
+	l := p.Background.Lightness()
+	confidence := math.Min(math.Abs(l-lightnessThreshold)/lightnessThreshold, 1)
+	if l < lightnessThreshold {
+		return Dark, confidence
+	}
+	return Light, confidence
+}
@@ -0,0 +1,60 @@
+package palette
+
+import "math"
+
+// ContrastRatio returns the WCAG 2.x contrast ratio between two colors, from
+// 1 (identical luminance) to 21 (black on white). The order does not matter.
+func ContrastRatio(a, b Color) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// APCA constants (APCA-W3 0.0.98G-4g).
+const (
+	apcaBlackThreshold = 0.022
+	apcaBlackClamp     = 1.414
+	apcaNormBG         = 0.56
+	apcaNormText       = 0.57
+	apcaRevText        = 0.62
+	apcaRevBG          = 0.65
+	apcaScale          = 1.14
+	apcaLowOffset      = 0.027
+	apcaLowClip        = 0.1
+	apcaDeltaYMin      = 0.0005
+)
+
+// apcaY is the screen luminance estimate used by APCA.
+func (c Color) apcaY() float64 {
+	y := 0.2126729*math.Pow(float64(c.R)/255, 2.4) +
+		0.7151522*math.Pow(float64(c.G)/255, 2.4) +
+		0.0721750*math.Pow(float64(c.B)/255, 2.4)
+	if y < apcaBlackThreshold {
+		y += math.Pow(apcaBlackThreshold-y, apcaBlackClamp)
+	}
+	return y
+}
+
+// APCA returns the APCA lightness contrast (Lc) of text on background. It is
+// positive for dark text on a light background, negative for light text on a
+// dark background, and roughly between -108 and 106.
+func APCA(text, background Color) float64 {
+	yt, yb := text.apcaY(), background.apcaY()
+	if math.Abs(yb-yt) < apcaDeltaYMin {
+		return 0
+	}
+	if yb > yt {
+		sapc := (math.Pow(yb, apcaNormBG) - math.Pow(yt, apcaNormText)) * apcaScale
+		if sapc < apcaLowClip {
+			return 0
+		}
+		return (sapc - apcaLowOffset) * 100
+	}
+	sapc := (math.Pow(yb, apcaRevBG) - math.Pow(yt, apcaRevText)) * apcaScale
+	if sapc > -apcaLowClip {
+		return 0
+	}
+	return (sapc + apcaLowOffset) * 100
+}
@@ -0,0 +1,121 @@
+package palette
+
+import "math"
+
+// Deficiency is a type of color vision deficiency.
+type Deficiency int
+
+const (
+	NoDeficiency Deficiency = iota
+	Protanopia
+	Deuteranopia
+	Tritanopia
+)
+
+// Deficiencies lists the simulated deficiencies.
+var Deficiencies = []Deficiency{Protanopia, Deuteranopia, Tritanopia}
+
+func (d Deficiency) String() string {
+	switch d {
+	case Protanopia:
+		return "protanopia"
+	case Deuteranopia:
+		return "deuteranopia"
+	case Tritanopia:
+		return "tritanopia"
+	}
+	return "normal vision"
+}
+
+// cvdMatrices are the simulation matrices of Machado, Oliveira and Fernandes
+// (2009) for severity 1, applied to linear RGB.
+var cvdMatrices = map[Deficiency][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// gamma converts a linear light value back to an 8 bit sRGB channel.
+func gamma(v float64) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(v * 255))
+}
+
+// Simulate returns the color as seen with the given deficiency.
+func (c Color) Simulate(d Deficiency) Color {
+	m, ok := cvdMatrices[d]
+	if !ok {
+		return c
+	}
+	r, g, b := linear(c.R), linear(c.G), linear(c.B)
+	return Color{
+		R: gamma(m[0][0]*r + m[0][1]*g + m[0][2]*b),
+		G: gamma(m[1][0]*r + m[1][1]*g + m[1][2]*b),
+		B: gamma(m[2][0]*r + m[2][1]*g + m[2][2]*b),
+	}
+}
+
+func simulateOptional(c *Color, d Deficiency) *Color {
+	if c == nil {
+		return nil
+	}
+	s := c.Simulate(d)
+	return &s
+}
+
+// Simulate returns the palette as seen with the given deficiency.
+func (p Palette) Simulate(d Deficiency) Palette {
+	s := Palette{
+		Background:          p.Background.Simulate(d),
+		Foreground:          p.Foreground.Simulate(d),
+		Cursor:              simulateOptional(p.Cursor, d),
+		CursorText:          simulateOptional(p.CursorText, d),
+		SelectionBackground: simulateOptional(p.SelectionBackground, d),
+		SelectionText:       simulateOptional(p.SelectionText, d),
+	}
+	for i := range p.Normal {
+		s.Normal[i] = p.Normal[i].Simulate(d)
+		s.Bright[i] = p.Bright[i].Simulate(d)
+	}
+	return s
+}
+
+// ANSI indices of the colors most often confused with a deficiency.
+const (
+	ansiRed    = 1
+	ansiGreen  = 2
+	ansiYellow = 3
+)
+
+// ConfusionDistance returns the smallest perceptual distance (DeltaE) between
+// red, green and yellow, normal and bright, as seen with the deficiency.
+// Values below about 10 mean that e.g. errors and successes in a diff or a
+// test report are hard to tell apart.
+func (p Palette) ConfusionDistance(d Deficiency) float64 {
+	s := p.Simulate(d)
+	pairs := [][2]int{{ansiRed, ansiGreen}, {ansiRed, ansiYellow}, {ansiGreen, ansiYellow}}
+	min := math.Inf(1)
+	for _, colors := range [][8]Color{s.Normal, s.Bright} {
+		for _, pair := range pairs {
+			min = math.Min(min, DeltaE(colors[pair[0]], colors[pair[1]]))
+		}
+	}
+	return min
+}
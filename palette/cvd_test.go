@@ -0,0 +1,52 @@
+package palette
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeltaE tests the perceptual distance in OKLab.
+func TestDeltaE(t *testing.T) {
+	black, white := Color{}, Color{R: 255, G: 255, B: 255}
+	assert.InDelta(t, 100, DeltaE(black, white), 0.1)
+	assert.Equal(t, 0.0, DeltaE(white, white))
+
+	l, a, b := white.OKLab()
+	assert.InDelta(t, 1, l, 0.001)
+	assert.InDelta(t, 0, a, 0.001)
+	assert.InDelta(t, 0, b, 0.001)
+}
+
+// TestSimulate tests that greys are unaffected and that red and green collapse for protanopia.
+func TestSimulate(t *testing.T) {
+	grey := Color{R: 128, G: 128, B: 128}
+	for _, d := range Deficiencies {
+		simulated := grey.Simulate(d)
+		assert.InDelta(t, 0, DeltaE(grey, simulated), 1, "Grey should stay grey for %s", d)
+	}
+	assert.Equal(t, grey, grey.Simulate(NoDeficiency))
+
+	red, _ := ParseColor("#b35900")
+	green, _ := ParseColor("#668000")
+	normal := DeltaE(red, green)
+	protan := DeltaE(red.Simulate(Protanopia), green.Simulate(Protanopia))
+	assert.Less(t, protan, normal/2, "Red and green should get much closer for protanopia")
+}
+
+// TestConfusionDistance tests the red/green/yellow audit metric.
+func TestConfusionDistance(t *testing.T) {
+	p, err := Parse([]byte(mockTheme))
+	assert.NoError(t, err)
+
+	audit := p.Audit()
+	assert.Len(t, audit.CVD, 3)
+	for _, d := range Deficiencies {
+		assert.Equal(t, p.ConfusionDistance(d), audit.CVD[d])
+	}
+
+	// A theme whose red and green only differ in hue is a problem for protanopia
+	p.Normal[ansiRed], _ = ParseColor("#b35900")
+	p.Normal[ansiGreen], _ = ParseColor("#668000")
+	assert.Less(t, p.ConfusionDistance(Protanopia), float64(MinConfusionDistance))
+}
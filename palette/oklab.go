@@ -0,0 +1,66 @@
+package palette
+
+import "math"
+
+// OKLab returns the color in the OKLab perceptual color space.
+func (c Color) OKLab() (l, a, b float64) {
+	r, g, bl := linear(c.R), linear(c.G), linear(c.B)
+	lms := [3]float64{
+		math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl),
+		math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl),
+		math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl),
+	}
+	l = 0.2104542553*lms[0] + 0.7936177850*lms[1] - 0.0040720468*lms[2]
+	a = 1.9779984951*lms[0] - 2.4285922050*lms[1] + 0.4505937099*lms[2]
+	b = 0.0259040371*lms[0] + 0.7827717662*lms[1] - 0.8086757660*lms[2]
+	return l, a, b
+}
+
+// DeltaE returns the perceptual distance between two colors: the euclidean
+// distance in OKLab, scaled by 100. Black and white are 100 apart and
+// differences below about 2 are hard to notice.
+func DeltaE(x, y Color) float64 {
+	l1, a1, b1 := x.OKLab()
+	l2, a2, b2 := y.OKLab()
+	return 100 * math.Sqrt((l1-l2)*(l1-l2)+(a1-a2)*(a1-a2)+(b1-b2)*(b1-b2))
+}
+
+// Distance returns the perceptual distance between two palettes: the mean
+// DeltaE over the background, the foreground and the 16 ANSI colors.
+func Distance(p, q Palette) float64 {
+	sum := DeltaE(p.Background, q.Background) + DeltaE(p.Foreground, q.Foreground)
+	for i := 0; i < 16; i++ {
+		sum += DeltaE(p.ANSI(i), q.ANSI(i))
+	}
+	return sum / 18
+}
+
+// hueRanges maps OKLCH hue angles (in degrees) to color names.
+var hueRanges = []struct {
+	name string
+	to   float64
+}{
+	{"red", 50}, {"orange", 85}, {"yellow", 120}, {"green", 175},
+	{"cyan", 230}, {"blue", 290}, {"purple", 335}, {"pink", 360},
+}
+
+// greyChroma is the OKLCH chroma below which a color has no discernible hue.
+const greyChroma = 0.012
+
+// HueNames lists the names returned by Hue.
+var HueNames = []string{"grey", "red", "orange", "yellow", "green", "cyan", "blue", "purple", "pink"}
+
+// Hue returns the name of the color's hue, or "grey" for unsaturated colors.
+func (c Color) Hue() string {
+	_, a, b := c.OKLab()
+	if math.Hypot(a, b) < greyChroma {
+		return "grey"
+	}
+	angle := math.Mod(math.Atan2(b, a)*180/math.Pi+360, 360)
+	for _, r := range hueRanges {
+		if angle < r.to {
+			return r.name
+		}
+	}
+	return "pink"
+}
@@ -0,0 +1,28 @@
+package palette
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHue tests the hue names of a few well known colors and backgrounds.
+func TestHue(t *testing.T) {
+	hues := map[string]string{
+		"#ff0000": "red",
+		"#ffa500": "orange",
+		"#ffff00": "yellow",
+		"#00ff00": "green",
+		"#00ffff": "cyan",
+		"#0000ff": "blue",
+		"#800080": "purple",
+		"#ff69b4": "pink",
+		"#282828": "grey",
+		"#1e1e2e": "blue",
+		"#fdf6e3": "yellow",
+	}
+	for hex, hue := range hues {
+		c, _ := ParseColor(hex)
+		assert.Equal(t, hue, c.Hue(), "Unexpected hue for %s", hex)
+	}
+}
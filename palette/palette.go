@@ -0,0 +1,215 @@
+package palette
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// ANSINames are the names of the eight ANSI colors in the order used by
+// [colors.normal] and [colors.bright].
+var ANSINames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ErrNoPrimaryColors is returned for theme files without a primary background or foreground.
+var ErrNoPrimaryColors = errors.New("theme has no primary colors")
+
+// Palette holds the colors defined by an alacritty theme file. Cursor and
+// selection colors are optional (or refer to the cell colors), in which case
+// they are nil.
+type Palette struct {
+	Background          Color
+	Foreground          Color
+	Cursor              *Color
+	CursorText          *Color
+	SelectionBackground *Color
+	SelectionText       *Color
+	Normal              [8]Color
+	Bright              [8]Color
+}
+
+type rawANSI struct {
+	Black   string `toml:"black"`
+	Red     string `toml:"red"`
+	Green   string `toml:"green"`
+	Yellow  string `toml:"yellow"`
+	Blue    string `toml:"blue"`
+	Magenta string `toml:"magenta"`
+	Cyan    string `toml:"cyan"`
+	White   string `toml:"white"`
+}
+
+func (r rawANSI) values() [8]string {
+	return [8]string{r.Black, r.Red, r.Green, r.Yellow, r.Blue, r.Magenta, r.Cyan, r.White}
+}
+
+type rawTheme struct {
+	Colors struct {
+		Primary struct {
+			Background string `toml:"background"`
+			Foreground string `toml:"foreground"`
+		} `toml:"primary"`
+		Cursor struct {
+			Text   string `toml:"text"`
+			Cursor string `toml:"cursor"`
+		} `toml:"cursor"`
+		Selection struct {
+			Text       string `toml:"text"`
+			Background string `toml:"background"`
+		} `toml:"selection"`
+		Normal rawANSI `toml:"normal"`
+		Bright rawANSI `toml:"bright"`
+	} `toml:"colors"`
+}
+
+// Load reads and parses the theme file at path.
+func Load(path string) (*Palette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse parses the [colors] section of an alacritty theme.
+func Parse(data []byte) (*Palette, error) {
+	var raw rawTheme
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	primary := raw.Colors.Primary
+	if primary.Background == "" || primary.Foreground == "" {
+		return nil, ErrNoPrimaryColors
+	}
+
+	p := &Palette{}
+	var err error
+	if p.Background, err = ParseColor(primary.Background); err != nil {
+		return nil, err
+	}
+	if p.Foreground, err = ParseColor(primary.Foreground); err != nil {
+		return nil, err
+	}
+	if p.Cursor, err = parseOptional(raw.Colors.Cursor.Cursor); err != nil {
+		return nil, err
+	}
+	if p.CursorText, err = parseOptional(raw.Colors.Cursor.Text); err != nil {
+		return nil, err
+	}
+	if p.SelectionBackground, err = parseOptional(raw.Colors.Selection.Background); err != nil {
+		return nil, err
+	}
+	if p.SelectionText, err = parseOptional(raw.Colors.Selection.Text); err != nil {
+		return nil, err
+	}
+	for i, v := range raw.Colors.Normal.values() {
+		if v == "" {
+			continue
+		}
+		if p.Normal[i], err = ParseColor(v); err != nil {
+			return nil, err
+		}
+	}
+	for i, v := range raw.Colors.Bright.values() {
+		if v == "" {
+			p.Bright[i] = p.Normal[i]
+			continue
+		}
+		if p.Bright[i], err = ParseColor(v); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// parseOptional parses a color which may be missing or refer to the cell
+// colors ("CellForeground", "CellBackground").
+func parseOptional(s string) (*Color, error) {
+	if s == "" || strings.HasPrefix(s, "Cell") {
+		return nil, nil
+	}
+	c, err := ParseColor(s)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ANSI returns the color of terminal color index i (0-15).
+func (p Palette) ANSI(i int) Color {
+	if i < 8 {
+		return p.Normal[i]
+	}
+	return p.Bright[i-8]
+}
+
+// Slot is a named color of a palette. Color is nil for optional colors the
+// theme does not define.
+type Slot struct {
+	Name  string
+	Color *Color
+}
+
+// Slots returns every color of the palette: the primary, cursor and selection
+// colors followed by the 16 terminal colors.
+func (p Palette) Slots() []Slot {
+	slots := []Slot{
+		{"background", &p.Background},
+		{"foreground", &p.Foreground},
+		{"cursor", p.Cursor},
+		{"cursor text", p.CursorText},
+		{"selection", p.SelectionBackground},
+		{"selection text", p.SelectionText},
+	}
+	for i := 0; i < 16; i++ {
+		name := ANSINames[i%8]
+		if i >= 8 {
+			name = "bright " + name
+		}
+		c := p.ANSI(i)
+		slots = append(slots, Slot{name, &c})
+	}
+	return slots
+}
+
+// Setting is a color of the [colors] table of an alacritty config, e.g. Key
+// "primary.background" and Value "#1a1b26".
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// Settings returns every color of the palette as alacritty settings, in the
+// order of Slots. Optional colors the theme leaves out get alacritty's
+// defaults, so that those of another theme do not stay.
+func (p Palette) Settings() []Setting {
+	setting := func(key string, c *Color, fallback string) Setting {
+		if c == nil {
+			return Setting{key, fallback}
+		}
+		return Setting{key, c.Hex()}
+	}
+	settings := []Setting{
+		setting("primary.background", &p.Background, ""),
+		setting("primary.foreground", &p.Foreground, ""),
+		setting("cursor.cursor", p.Cursor, "CellForeground"),
+		setting("cursor.text", p.CursorText, "CellBackground"),
+		setting("selection.background", p.SelectionBackground, "CellForeground"),
+		setting("selection.text", p.SelectionText, "CellBackground"),
+	}
+	for i := 0; i < 16; i++ {
+		table := "normal."
+		if i >= 8 {
+			table = "bright."
+		}
+		c := p.ANSI(i)
+		settings = append(settings, setting(table+ANSINames[i%8], &c, ""))
+	}
+	return settings
+}
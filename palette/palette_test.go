@@ -0,0 +1,189 @@
+package palette
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockTheme is a sample alacritty theme (dracula) for testing purposes.
+const mockTheme = `
+[colors.primary]
+background = '#282a36'
+foreground = '#f8f8f2'
+
+[colors.cursor]
+text = 'CellBackground'
+cursor = '#f8f8f2'
+
+[colors.selection]
+text = 'CellForeground'
+background = '#44475a'
+
+[colors.normal]
+black = '#21222c'
+red = '#ff5555'
+green = '#50fa7b'
+yellow = '#f1fa8c'
+blue = '#bd93f9'
+magenta = '#ff79c6'
+cyan = '#8be9fd'
+white = '#f8f8f2'
+
+[colors.bright]
+black = '#6272a4'
+red = '#ff6e6e'
+green = '#69ff94'
+yellow = '#ffffa5'
+blue = '#d6acff'
+magenta = '#ff92df'
+cyan = '#a4ffff'
+white = '#ffffff'
+`
+
+// TestParseColor tests both hex notations used by alacritty themes.
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("#ff8000")
+	assert.NoError(t, err)
+	assert.Equal(t, Color{R: 0xff, G: 0x80, B: 0x00}, c)
+
+	c, err = ParseColor("0x1E1E2E")
+	assert.NoError(t, err)
+	assert.Equal(t, "#1e1e2e", c.Hex())
+
+	for _, invalid := range []string{"", "ff8000", "#fff", "#gg0000"} {
+		_, err = ParseColor(invalid)
+		assert.Error(t, err, "Expected an error for %q", invalid)
+	}
+}
+
+// TestHSL tests the conversion to hue, saturation and lightness.
+func TestHSL(t *testing.T) {
+	tests := []struct {
+		hex     string
+		h, s, l float64
+	}{
+		{"#000000", 0, 0, 0},
+		{"#ffffff", 0, 0, 100},
+		{"#ff0000", 0, 100, 50},
+		{"#00ff00", 120, 100, 50},
+		{"#0000ff", 240, 100, 50},
+		{"#ff00ff", 300, 100, 50},
+		{"#bd93f9", 264.7, 89.5, 77.6},
+	}
+	for _, tt := range tests {
+		c, _ := ParseColor(tt.hex)
+		h, s, l := c.HSL()
+		assert.InDelta(t, tt.h, h, 0.1, "hue of %s", tt.hex)
+		assert.InDelta(t, tt.s, s, 0.1, "saturation of %s", tt.hex)
+		assert.InDelta(t, tt.l, l, 0.1, "lightness of %s", tt.hex)
+	}
+}
+
+// TestLoad tests reading a theme file into a Palette.
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dracula.toml")
+	assert.NoError(t, os.WriteFile(path, []byte(mockTheme), 0644))
+
+	p, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "#282a36", p.Background.Hex())
+	assert.Equal(t, "#f8f8f2", p.Foreground.Hex())
+	assert.Nil(t, p.CursorText, "CellBackground should leave the cursor text unset")
+	assert.Equal(t, "#f8f8f2", p.Cursor.Hex())
+	assert.Nil(t, p.SelectionText)
+	assert.Equal(t, "#44475a", p.SelectionBackground.Hex())
+	assert.Equal(t, "#ff5555", p.ANSI(1).Hex())
+	assert.Equal(t, "#ffffff", p.ANSI(15).Hex())
+}
+
+// TestSlots tests that every color of a palette is listed under its name.
+func TestSlots(t *testing.T) {
+	p, err := Parse([]byte(mockTheme))
+	assert.NoError(t, err)
+
+	slots := p.Slots()
+	assert.Len(t, slots, 22)
+	assert.Equal(t, "background", slots[0].Name)
+	assert.Equal(t, "#282a36", slots[0].Color.Hex())
+	assert.Equal(t, "cursor text", slots[3].Name)
+	assert.Nil(t, slots[3].Color)
+	assert.Equal(t, "bright white", slots[21].Name)
+	assert.Equal(t, "#ffffff", slots[21].Color.Hex())
+}
+
+// TestSettings tests the alacritty settings of a palette, defaults included.
+func TestSettings(t *testing.T) {
+	p, err := Parse([]byte(mockTheme))
+	assert.NoError(t, err)
+
+	settings := p.Settings()
+	assert.Len(t, settings, 22)
+	assert.Equal(t, Setting{"primary.background", "#282a36"}, settings[0])
+	assert.Equal(t, Setting{"cursor.text", "CellBackground"}, settings[3])
+	assert.Equal(t, Setting{"normal.red", p.Normal[1].Hex()}, settings[7])
+	assert.Equal(t, Setting{"bright.white", "#ffffff"}, settings[21])
+}
+
+// TestParseWithoutPrimaryColors tests that themes without a background are rejected.
+func TestParseWithoutPrimaryColors(t *testing.T) {
+	_, err := Parse([]byte("[colors.normal]\nred = '#ff0000'\n"))
+	assert.ErrorIs(t, err, ErrNoPrimaryColors)
+
+	_, err = Parse(nil)
+	assert.ErrorIs(t, err, ErrNoPrimaryColors)
+}
+
+// TestClassify tests the dark/light classification and its confidence.
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		background string
+		class      Class
+		minConf    float64
+		maxConf    float64
+	}{
+		{"#000000", Dark, 1, 1},
+		{"#282a36", Dark, 0.6, 0.9},
+		{"#fdf6e3", Light, 0.9, 1},
+		{"#ffffff", Light, 1, 1},
+		{"#707070", Dark, 0, 0.1},
+	}
+	for _, tt := range tests {
+		bg, _ := ParseColor(tt.background)
+		class, confidence := Palette{Background: bg}.Classify()
+		assert.Equal(t, tt.class, class, "Unexpected class for %s", tt.background)
+		assert.GreaterOrEqual(t, confidence, tt.minConf, "Confidence too low for %s", tt.background)
+		assert.LessOrEqual(t, confidence, tt.maxConf, "Confidence too high for %s", tt.background)
+	}
+}
+
+// TestRecolor tests that ANSI colors are rewritten to the palette's 24-bit colors.
+func TestRecolor(t *testing.T) {
+	p, err := Parse([]byte(mockTheme))
+	assert.NoError(t, err)
+
+	out := p.Recolor("\x1b[31mred\x1b[m plain\n\x1b[1;104mbright\x1b[0m \x1b[38;5;2mgreen\x1b[38;5;200mpink")
+	assert.Contains(t, out, "\x1b[38;2;255;85;85mred", "31 should become the palette's red")
+	assert.Contains(t, out, "\x1b[1;48;2;214;172;255mbright", "Bold should be kept and 104 become bright blue")
+	assert.Contains(t, out, "\x1b[38;2;80;250;123mgreen", "256-color indices below 16 should be recolored")
+	assert.Contains(t, out, "\x1b[38;5;200mpink", "Other 256-color indices should be left alone")
+	assert.Contains(t, out, "\x1b[0m\n", "Lines should end with a reset")
+	assert.Contains(t, out, "\x1b[0;38;2;248;248;242;48;2;40;42;54m plain", "Resets should restore the palette's default colors")
+}
+
+// TestRecolorOtherSequences tests that sequences other than SGR and invalid
+// color indices are passed through.
+func TestRecolorOtherSequences(t *testing.T) {
+	p, err := Parse([]byte(mockTheme))
+	assert.NoError(t, err)
+
+	out := p.Recolor("\x1b[2J\x1b[1;1Htop\x1b[K\x1b[31mred\x1b[?25lhidden")
+	assert.Contains(t, out, "\x1b[2J\x1b[1;1Htop\x1b[K", "Erasing and moving should be left alone")
+	assert.Contains(t, out, "\x1b[38;2;255;85;85mred", "SGR after other sequences should still be recolored")
+	assert.Contains(t, out, "\x1b[?25lhidden")
+
+	assert.NotPanics(t, func() { out = p.Recolor("\x1b[38;5;-1mnegative") })
+	assert.Contains(t, out, "\x1b[38;5;-1mnegative", "Invalid indices should be left alone")
+}
@@ -0,0 +1,137 @@
+// Package portal reads the desktop's color scheme preference from the
+// settings interface of the XDG desktop portal, which GNOME and KDE provide
+// on the session bus.
+package portal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName     = "org.freedesktop.portal.Desktop"
+	objectPath  = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	settingsIfc = "org.freedesktop.portal.Settings"
+	namespace   = "org.freedesktop.appearance"
+	key         = "color-scheme"
+)
+
+// ColorScheme is the value of the org.freedesktop.appearance color-scheme
+// setting.
+type ColorScheme uint32
+
+const (
+	NoPreference ColorScheme = iota
+	PreferDark
+	PreferLight
+)
+
+func (c ColorScheme) String() string {
+	switch c {
+	case PreferDark:
+		return "prefer-dark"
+	case PreferLight:
+		return "prefer-light"
+	}
+	return "no preference"
+}
+
+// Settings reads the color scheme from the portal.
+type Settings struct {
+	conn *dbus.Conn
+	obj  dbus.BusObject
+}
+
+// Connect connects to the session bus.
+func Connect() (*Settings, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the session bus: %w", err)
+	}
+	return New(conn), nil
+}
+
+// New uses an existing connection, e.g. to a private bus.
+func New(conn *dbus.Conn) *Settings {
+	return &Settings{conn: conn, obj: conn.Object(busName, objectPath)}
+}
+
+// Close closes the connection, which also ends Watch.
+func (s *Settings) Close() error {
+	return s.conn.Close()
+}
+
+// ColorScheme returns the current preference. A portal without the setting
+// has no preference.
+func (s *Settings) ColorScheme() (ColorScheme, error) {
+	var v dbus.Variant
+	err := s.obj.Call(settingsIfc+".ReadOne", 0, namespace, key).Store(&v)
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) && dbusErr.Name == "org.freedesktop.DBus.Error.UnknownMethod" {
+		// Portals before version 2 only have Read, which wraps the value
+		// in another variant
+		err = s.obj.Call(settingsIfc+".Read", 0, namespace, key).Store(&v)
+	}
+	if errors.As(err, &dbusErr) && dbusErr.Name == "org.freedesktop.portal.Error.NotFound" {
+		return NoPreference, nil
+	}
+	if err != nil {
+		return NoPreference, fmt.Errorf("reading %s %s: %w", namespace, key, err)
+	}
+	return parse(v)
+}
+
+// Watch returns the new preference whenever it changes. The channel is
+// closed with the connection.
+func (s *Settings) Watch() (<-chan ColorScheme, error) {
+	err := s.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(objectPath),
+		dbus.WithMatchInterface(settingsIfc),
+		dbus.WithMatchMember("SettingChanged"),
+		dbus.WithMatchArg(0, namespace),
+		dbus.WithMatchArg(1, key),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("subscribing to SettingChanged: %w", err)
+	}
+	signals := make(chan *dbus.Signal, 8)
+	s.conn.Signal(signals)
+
+	schemes := make(chan ColorScheme)
+	go func() {
+		defer close(schemes)
+		for sig := range signals {
+			if sig.Name != settingsIfc+".SettingChanged" || len(sig.Body) != 3 ||
+				sig.Body[0] != namespace || sig.Body[1] != key {
+				continue
+			}
+			v, ok := sig.Body[2].(dbus.Variant)
+			if !ok {
+				continue
+			}
+			if scheme, err := parse(v); err == nil {
+				schemes <- scheme
+			}
+		}
+	}()
+	return schemes, nil
+}
+
+// parse returns the color scheme held by v, unwrapping nested variants.
+func parse(v dbus.Variant) (ColorScheme, error) {
+	value := v.Value()
+	for {
+		inner, ok := value.(dbus.Variant)
+		if !ok {
+			break
+		}
+		value = inner.Value()
+	}
+	n, ok := value.(uint32)
+	if !ok || n > uint32(PreferLight) {
+		return NoPreference, fmt.Errorf("unexpected %s value %v", key, v)
+	}
+	return ColorScheme(n), nil
+}
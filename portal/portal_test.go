@@ -0,0 +1,163 @@
+package portal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// startBus starts a private session bus and returns its address. The test
+// is skipped when dbus-daemon is not installed.
+func startBus(t *testing.T) string {
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "bus.conf")
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(busConfig, dir)), 0644))
+
+	cmd := exec.Command(daemon, "--config-file="+configPath, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	return strings.TrimSpace(address)
+}
+
+// fakePortal serves the settings interface on the bus at address. Without
+// readOne it behaves like a portal before version 2.
+func fakePortal(t *testing.T, address string, scheme ColorScheme, readOne bool) *dbus.Conn {
+	conn, err := dbus.Connect(address)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	read := func(ns, k string) (dbus.Variant, *dbus.Error) {
+		if ns != namespace || k != key {
+			return dbus.Variant{}, dbus.NewError("org.freedesktop.portal.Error.NotFound", []any{"no such setting"})
+		}
+		return dbus.MakeVariant(uint32(scheme)), nil
+	}
+	methods := map[string]any{
+		"Read": func(ns, k string) (dbus.Variant, *dbus.Error) {
+			v, err := read(ns, k)
+			return dbus.MakeVariant(v), err
+		},
+	}
+	if readOne {
+		methods["ReadOne"] = read
+	}
+	require.NoError(t, conn.ExportMethodTable(methods, objectPath, settingsIfc))
+	reply, err := conn.RequestName(busName, dbus.NameFlagDoNotQueue)
+	require.NoError(t, err)
+	require.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+	return conn
+}
+
+// connect returns Settings on a new connection to the bus at address.
+func connect(t *testing.T, address string) *Settings {
+	conn, err := dbus.Connect(address)
+	require.NoError(t, err)
+	s := New(conn)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// TestColorScheme tests reading the preference from current and old portals
+func TestColorScheme(t *testing.T) {
+	address := startBus(t)
+	fakePortal(t, address, PreferDark, true)
+
+	scheme, err := connect(t, address).ColorScheme()
+	assert.NoError(t, err)
+	assert.Equal(t, PreferDark, scheme)
+
+	address = startBus(t)
+	fakePortal(t, address, PreferLight, false)
+
+	scheme, err = connect(t, address).ColorScheme()
+	assert.NoError(t, err)
+	assert.Equal(t, PreferLight, scheme)
+
+	// Without a portal the call fails
+	_, err = connect(t, startBus(t)).ColorScheme()
+	assert.Error(t, err)
+}
+
+// TestWatch tests that changes of the color scheme are reported and that
+// other settings are ignored
+func TestWatch(t *testing.T) {
+	address := startBus(t)
+	portal := fakePortal(t, address, NoPreference, true)
+	s := connect(t, address)
+	schemes, err := s.Watch()
+	require.NoError(t, err)
+
+	emit := func(ns, k string, value any) {
+		assert.NoError(t, portal.Emit(objectPath, settingsIfc+".SettingChanged", ns, k, dbus.MakeVariant(value)))
+	}
+	next := func() ColorScheme {
+		select {
+		case scheme := <-schemes:
+			return scheme
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a color scheme")
+		}
+		return NoPreference
+	}
+
+	emit(namespace, "accent-color", uint32(1))
+	emit(namespace, key, "dark")
+	emit(namespace, key, uint32(PreferDark))
+	assert.Equal(t, PreferDark, next())
+	emit(namespace, key, uint32(PreferLight))
+	assert.Equal(t, PreferLight, next())
+
+	s.Close()
+	select {
+	case _, ok := <-schemes:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not end with the connection")
+	}
+}
+
+// TestParse tests unwrapping the setting's value
+func TestParse(t *testing.T) {
+	scheme, err := parse(dbus.MakeVariant(dbus.MakeVariant(uint32(2))))
+	assert.NoError(t, err)
+	assert.Equal(t, PreferLight, scheme)
+
+	_, err = parse(dbus.MakeVariant(uint32(3)))
+	assert.Error(t, err)
+	_, err = parse(dbus.MakeVariant(int32(1)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+// Package project finds the theme a directory asks for. A directory opts in
+// with a marker file which is either a theme's name, or TOML with a
+// [project] section naming a theme or a filter query:
+//
+//	[project]
+//	filter = "dark red"
+//
+// The marker applies to the directory and everything below it.
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+
+	it "goalacritty_themes/theme_tools"
+)
+
+// MarkerFile is the name of the marker file.
+const MarkerFile = ".alacritty-theme"
+
+// Marker is a parsed marker file.
+type Marker struct {
+	Path string
+	it.Selector
+}
+
+// Find returns the marker of dir, found in dir or the nearest of its parents.
+func Find(dir string) (Marker, bool, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Marker{}, false, err
+	}
+	for {
+		path := filepath.Join(dir, MarkerFile)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			sel, err := Parse(data)
+			if err != nil {
+				return Marker{}, false, fmt.Errorf("%s: %w", path, err)
+			}
+			return Marker{Path: path, Selector: sel}, true, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return Marker{}, false, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Marker{}, false, nil
+		}
+		dir = parent
+	}
+}
+
+// Parse reads the contents of a marker file.
+func Parse(data []byte) (it.Selector, error) {
+	if tree, err := toml.LoadBytes(data); err == nil && tree.Has("project") {
+		var file struct {
+			Project struct {
+				Theme  string `toml:"theme"`
+				Filter string `toml:"filter"`
+			} `toml:"project"`
+		}
+		if err := tree.Unmarshal(&file); err != nil {
+			return it.Selector{}, err
+		}
+		return it.NewSelector(file.Project.Theme, file.Project.Filter)
+	}
+	// Otherwise the first line which is not a comment names the theme
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return it.NewSelector(line, "")
+		}
+	}
+	return it.Selector{}, errors.New("names no theme")
+}
@@ -0,0 +1,67 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse tests both forms of marker files
+func TestParse(t *testing.T) {
+	sel, err := Parse([]byte("# production\ngruvbox_dark\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "gruvbox_dark", sel.Theme)
+
+	sel, err = Parse([]byte("[project]\nfilter = \"dark red\"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "dark red", sel.Filter)
+
+	sel, err = Parse([]byte("[project]\ntheme = \"dracula\"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "dracula", sel.Theme)
+
+	_, err = Parse([]byte("[project]\ntheme = \"dracula\"\nfilter = \"dark\"\n"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("[project]\nfilter = \"contrast:high\"\n"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("# nothing\n\n"))
+	assert.Error(t, err)
+	// A checkout cannot point at files outside of the theme directories
+	_, err = Parse([]byte("../../.ssh/config\n"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("[project]\ntheme = \"/etc/passwd\"\n"))
+	assert.Error(t, err)
+}
+
+// TestFind tests that the nearest marker file applies
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	prod := filepath.Join(root, "prod")
+	nested := filepath.Join(prod, "service", "cmd")
+	assert.NoError(t, os.MkdirAll(nested, 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "scratch"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(prod, MarkerFile), []byte("red_alert\n"), 0644))
+
+	m, ok, err := Find(nested)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(prod, MarkerFile), m.Path)
+	assert.Equal(t, "red_alert", m.Theme)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(prod, "service", MarkerFile), []byte("[project]\ntheme = \"nord\"\n"), 0644))
+	m, ok, err = Find(nested)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "nord", m.Theme)
+
+	_, ok, err = Find(filepath.Join(root, "scratch"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// A broken marker is reported rather than skipped
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "scratch", MarkerFile), []byte("[project]\n"), 0644))
+	_, _, err = Find(filepath.Join(root, "scratch"))
+	assert.Error(t, err)
+}
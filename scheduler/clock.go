@@ -0,0 +1,16 @@
+package scheduler
+
+import "time"
+
+// Clock tells the time and waits. The daemon uses it instead of the time
+// package so that tests can control the time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the real clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time                         { return time.Now() }
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
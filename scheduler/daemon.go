@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// CheckInterval is the longest the daemon sleeps. Timers do not run
+	// while the machine is suspended, so the daemon wakes up regularly to
+	// compare the rules with the wall clock.
+	CheckInterval = time.Minute
+	// jumpTolerance is how late the daemon may wake up before it reports
+	// that the clock jumped.
+	jumpTolerance = 5 * time.Second
+)
+
+// Daemon applies the theme of the rule in effect whenever it changes, and
+// while no rule is, the theme of the rotation.
+type Daemon struct {
+	Clock Clock
+	Rules []Rule
+	// Apply switches to the theme of the rule
+	Apply func(r Rule) error
+	// Rotation is nil if the themes do not rotate
+	Rotation *Rotation
+	// Rotate switches to the rotation's theme for the slot starting at
+	// slot and returns its name
+	Rotate func(slot time.Time) (string, error)
+	// Logf reports what the daemon does
+	Logf func(format string, a ...any)
+}
+
+// Run applies the rule in effect now, then follows the schedule until ctx is
+// done. A failure to apply a rule is logged and retried on the next wake up.
+func (d *Daemon) Run(ctx context.Context) error {
+	applied := -1         // the rule in effect, or -1 if none is or it was not applied
+	var rotated time.Time // the slot of the rotation applied, zero if none is
+	var expected time.Time
+	for {
+		// Only the wall clock, so that a suspend or a change of the clock
+		// shows up as a jump
+		now := d.Clock.Now().Round(0)
+		if !expected.IsZero() {
+			if drift := now.Sub(expected); drift > jumpTolerance || drift < -jumpTolerance {
+				d.Logf("the clock jumped by %s, checking the schedule", drift.Round(time.Second))
+			}
+		}
+
+		i, ok := Active(d.Rules, now)
+		switch {
+		case !ok:
+			applied = -1
+			if d.Rotation != nil {
+				rotated = d.rotate(now, rotated)
+			}
+		case i != applied:
+			rotated = time.Time{}
+			if err := d.Apply(d.Rules[i]); err != nil {
+				d.Logf("could not apply %s: %v", d.Rules[i], err)
+				applied = -1
+			} else {
+				d.Logf("applied %s", d.Rules[i])
+				applied = i
+			}
+		}
+
+		wait := CheckInterval
+		if next, ok := NextBoundary(d.Rules, now); ok && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		if d.Rotation != nil && d.Rotation.NextSlot(now).Sub(now) < wait {
+			wait = d.Rotation.NextSlot(now).Sub(now)
+		}
+		expected = now.Add(wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.Clock.After(wait):
+		}
+	}
+}
+
+// rotate applies the rotation's theme for the slot of now, unless the slot
+// rotated was applied already. It returns the slot applied.
+func (d *Daemon) rotate(now, rotated time.Time) time.Time {
+	slot := d.Rotation.Slot(now)
+	if slot.Equal(rotated) {
+		return rotated
+	}
+	theme, err := d.Rotate(slot)
+	if err != nil {
+		d.Logf("could not rotate the theme: %v", err)
+		return time.Time{}
+	}
+	d.Logf("applied %s of the rotation", theme)
+	return slot
+}
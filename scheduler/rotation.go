@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/state"
+	it "goalacritty_themes/theme_tools"
+)
+
+const (
+	// defaultAvoidRecent is how many recently used themes the rotation
+	// avoids if the config does not say
+	defaultAvoidRecent = 5
+	// minInterval is the shortest interval of a rotation.
+	minInterval = time.Minute
+)
+
+// ErrEmptyPlaylist is returned when no installed theme is in the playlist.
+var ErrEmptyPlaylist = errors.New("the rotation's playlist is empty")
+
+// Rotation cycles through a playlist of themes: the favorites, the themes
+// with a tag or the themes matching a filter. With an interval it moves on
+// to the next theme of the playlist at every multiple of the interval since
+// midnight; without, it chooses a theme of the day from the date.
+type Rotation struct {
+	Favorites   bool
+	Tag         string
+	Filter      string
+	query       it.Query
+	Interval    time.Duration
+	AvoidRecent int
+}
+
+// RotationFromConfig parses the [rotation] config section. It returns nil
+// if the section has no playlist.
+func RotationFromConfig(config cf.Config) (*Rotation, error) {
+	c := config.Rotation
+	sources := 0
+	for _, set := range []bool{c.Favorites, c.Tag != "", c.Filter != ""} {
+		if set {
+			sources++
+		}
+	}
+	switch {
+	case sources == 0 && c.Interval == "":
+		return nil, nil
+	case sources != 1:
+		return nil, errors.New("rotation: needs one of favorites, tag and filter")
+	}
+
+	r := &Rotation{Favorites: c.Favorites, Tag: c.Tag, Filter: c.Filter, AvoidRecent: defaultAvoidRecent}
+	if c.AvoidRecent != nil {
+		if *c.AvoidRecent < 0 {
+			return nil, errors.New("rotation: avoid_recent must not be negative")
+		}
+		r.AvoidRecent = *c.AvoidRecent
+	}
+	var err error
+	if r.query, err = it.ParseQuery(c.Filter); err != nil {
+		return nil, fmt.Errorf("rotation: %w", err)
+	}
+	if c.Interval != "" {
+		if r.Interval, err = time.ParseDuration(c.Interval); err != nil {
+			return nil, fmt.Errorf("rotation: invalid interval: %w", err)
+		}
+		if r.Interval < minInterval {
+			return nil, fmt.Errorf("rotation: the interval must be at least %s", minInterval)
+		}
+	}
+	return r, nil
+}
+
+func (r Rotation) String() string {
+	playlist := "favorites"
+	switch {
+	case r.Tag != "":
+		playlist = "themes tagged " + r.Tag
+	case r.Filter != "":
+		playlist = "themes matching " + r.Filter
+	}
+	if r.Interval == 0 {
+		return "a theme of the day from the " + playlist
+	}
+	return fmt.Sprintf("the %s, every %s", playlist, r.Interval)
+}
+
+// Playlist returns the names of the installed themes in the playlist.
+func (r Rotation) Playlist(themes []it.ThemeData, store *state.Store) []string {
+	var names []string
+	if r.Filter != "" {
+		for _, theme := range it.NewQueryIndex(themes).Filter(r.query) {
+			names = append(names, theme.Name)
+		}
+		return names
+	}
+	candidates := store.Favorites
+	if r.Tag != "" {
+		candidates = store.Tagged(r.Tag)
+	}
+	for _, name := range candidates {
+		if slices.ContainsFunc(themes, func(theme it.ThemeData) bool { return theme.Name == name }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Slot returns when the slot t is in started: midnight for a theme of the
+// day, otherwise the last multiple of the interval since midnight.
+func (r Rotation) Slot(t time.Time) time.Time {
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	if r.Interval == 0 {
+		return midnight
+	}
+	return midnight.Add(t.Sub(midnight) / r.Interval * r.Interval)
+}
+
+// NextSlot returns when the slot after the one t is in starts. The last
+// slot of a day ends at midnight, even if the interval does not divide a
+// day.
+func (r Rotation) NextSlot(t time.Time) time.Time {
+	y, m, d := t.Date()
+	next := time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	if r.Interval != 0 {
+		if end := r.Slot(t).Add(r.Interval); end.Before(next) {
+			next = end
+		}
+	}
+	return next
+}
+
+// Choose returns the theme of playlist for the slot starting at slot, which
+// follows one showing previous. The previous theme and the recently used
+// ones are avoided, as long as the playlist has others. With an interval
+// the theme following previous in the playlist is chosen, otherwise the
+// date decides.
+func (r Rotation) Choose(playlist []string, previous string, recent []string, slot time.Time) (string, error) {
+	if len(playlist) == 0 {
+		return "", ErrEmptyPlaylist
+	}
+	avoid := map[string]bool{previous: true}
+	for _, name := range recent[:min(r.AvoidRecent, len(recent))] {
+		avoid[name] = true
+	}
+	candidates := slices.DeleteFunc(slices.Clone(playlist), func(name string) bool { return avoid[name] })
+	if len(candidates) == 0 {
+		candidates = slices.DeleteFunc(slices.Clone(playlist), func(name string) bool { return name == previous })
+	}
+	if len(candidates) == 0 {
+		candidates = playlist
+	}
+
+	if r.Interval == 0 {
+		h := fnv.New32a()
+		h.Write([]byte(slot.Format(time.DateOnly)))
+		return candidates[h.Sum32()%uint32(len(candidates))], nil
+	}
+	start := slices.Index(playlist, previous)
+	for i := 1; i <= len(playlist); i++ {
+		if name := playlist[(start+i)%len(playlist)]; slices.Contains(candidates, name) {
+			return name, nil
+		}
+	}
+	return candidates[0], nil
+}
@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	cf "goalacritty_themes/config"
+	"goalacritty_themes/state"
+	it "goalacritty_themes/theme_tools"
+)
+
+// rotationConfig returns a config with a rotation of the favorites.
+func rotationConfig(interval string) cf.Config {
+	var config cf.Config
+	config.Rotation.Favorites = true
+	config.Rotation.Interval = interval
+	return config
+}
+
+// TestRotationFromConfig tests that the playlist and the interval are checked
+func TestRotationFromConfig(t *testing.T) {
+	r, err := RotationFromConfig(cf.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	r, err = RotationFromConfig(rotationConfig("2h"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, r.Interval)
+	assert.Equal(t, defaultAvoidRecent, r.AvoidRecent)
+	assert.Equal(t, "the favorites, every 2h0m0s", r.String())
+
+	config := cf.Config{}
+	config.Rotation.Tag = "work"
+	r, err = RotationFromConfig(config)
+	assert.NoError(t, err)
+	assert.Equal(t, "a theme of the day from the themes tagged work", r.String())
+
+	for _, invalid := range []cf.Config{rotationConfig("30s"), rotationConfig("often")} {
+		_, err := RotationFromConfig(invalid)
+		assert.Error(t, err)
+	}
+	config = rotationConfig("")
+	config.Rotation.Tag = "work"
+	_, err = RotationFromConfig(config)
+	assert.Error(t, err)
+	config = rotationConfig("")
+	config.Rotation.AvoidRecent = new(int)
+	r, err = RotationFromConfig(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, r.AvoidRecent, "0 avoids none")
+	*config.Rotation.AvoidRecent = -1
+	_, err = RotationFromConfig(config)
+	assert.EqualError(t, err, "rotation: avoid_recent must not be negative")
+	config = cf.Config{}
+	config.Rotation.Interval = "1h"
+	_, err = RotationFromConfig(config)
+	assert.Error(t, err)
+	config.Rotation.Filter = "hue:teal"
+	_, err = RotationFromConfig(config)
+	assert.Error(t, err)
+}
+
+// TestPlaylist tests that only installed themes are in the playlist
+func TestPlaylist(t *testing.T) {
+	themes := []it.ThemeData{{Name: "nord"}, {Name: "dracula"}, {Name: "gruvbox_dark"}}
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	store.AddFavorite("gruvbox_dark")
+	store.AddFavorite("uninstalled")
+	store.AddFavorite("nord")
+	store.AddTags("dracula", "work")
+
+	r, _ := RotationFromConfig(rotationConfig(""))
+	assert.Equal(t, []string{"gruvbox_dark", "nord"}, r.Playlist(themes, store))
+	r.Favorites, r.Tag = false, "work"
+	assert.Equal(t, []string{"dracula"}, r.Playlist(themes, store))
+}
+
+// TestSlot tests the slots of both kinds of rotations
+func TestSlot(t *testing.T) {
+	daily, _ := RotationFromConfig(rotationConfig(""))
+	assert.Equal(t, at(0, 0), daily.Slot(at(13, 37)))
+	assert.Equal(t, at(0, 0).AddDate(0, 0, 1), daily.NextSlot(at(13, 37)))
+
+	r, _ := RotationFromConfig(rotationConfig("2h"))
+	assert.Equal(t, at(12, 0), r.Slot(at(13, 37)))
+	assert.Equal(t, at(14, 0), r.NextSlot(at(13, 37)))
+	assert.Equal(t, at(14, 0), r.Slot(at(14, 0)))
+
+	// The last slot of the day is cut short at midnight
+	r, _ = RotationFromConfig(rotationConfig("5h"))
+	assert.Equal(t, at(20, 0), r.Slot(at(23, 0)))
+	assert.Equal(t, at(0, 0).AddDate(0, 0, 1), r.NextSlot(at(23, 0)))
+}
+
+// TestChoose tests cycling through the playlist and the theme of the day
+func TestChoose(t *testing.T) {
+	playlist := []string{"a", "b", "c", "d", "e"}
+	r, _ := RotationFromConfig(rotationConfig("1h"))
+	r.AvoidRecent = 2
+
+	choose := func(previous string, recent ...string) string {
+		theme, err := r.Choose(playlist, previous, recent, at(12, 0))
+		assert.NoError(t, err)
+		return theme
+	}
+	assert.Equal(t, "a", choose(""))
+	assert.Equal(t, "c", choose("b"))
+	assert.Equal(t, "a", choose("e"))
+	// Recently used themes are skipped, up to AvoidRecent of them
+	assert.Equal(t, "d", choose("b", "b", "c", "d"))
+	assert.Equal(t, "c", choose("b", "b", "x", "c"))
+	assert.Equal(t, "d", choose("b", "a", "c", "d", "e"))
+	// With AvoidRecent 0 only the previous theme is skipped
+	r.AvoidRecent = 0
+	assert.Equal(t, "c", choose("b", "c", "d"))
+	// Unless nothing else is left
+	r.AvoidRecent = 10
+	assert.Equal(t, "c", choose("b", "a", "c", "d", "e"))
+	theme, err := r.Choose([]string{"a"}, "a", []string{"a"}, at(12, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "a", theme)
+	_, err = r.Choose(nil, "a", nil, at(12, 0))
+	assert.ErrorIs(t, err, ErrEmptyPlaylist)
+
+	// The theme of the day only depends on the date and on what to avoid
+	daily, _ := RotationFromConfig(rotationConfig(""))
+	first, _ := daily.Choose(playlist, "", nil, at(0, 0))
+	again, _ := daily.Choose(playlist, "", nil, at(0, 0))
+	assert.Equal(t, first, again)
+	themes := map[string]bool{}
+	for day := 0; day < 30; day++ {
+		theme, err := daily.Choose(playlist, "", nil, at(0, 0).AddDate(0, 0, day))
+		assert.NoError(t, err)
+		themes[theme] = true
+	}
+	assert.Greater(t, len(themes), 1, "The theme of the day should change with the date")
+	skipped, _ := daily.Choose(playlist, first, []string{first}, at(0, 0))
+	assert.NotEqual(t, first, skipped)
+}
+
+// TestDaemonRotation tests that the rotation runs while no rule is in effect
+func TestDaemonRotation(t *testing.T) {
+	rules, err := ParseRules([]cf.ScheduleRule{{From: "09:00", To: "17:00", Theme: "work"}}, nil)
+	assert.NoError(t, err)
+	rotation, _ := RotationFromConfig(rotationConfig("2h"))
+	clock := &fakeClock{now: at(6, 30), waits: make(chan time.Duration)}
+	applied := make(chan string, 10)
+	d := &Daemon{
+		Clock: clock,
+		Rules: rules,
+		Apply: func(r Rule) error {
+			applied <- r.String()
+			return nil
+		},
+		Rotation: rotation,
+		Rotate: func(slot time.Time) (string, error) {
+			applied <- slot.Format("rotation 15:04")
+			return "theme", nil
+		},
+		Logf: func(format string, a ...any) {},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+
+	assert.Equal(t, CheckInterval, <-clock.waits)
+	assert.Equal(t, "rotation 06:00", <-applied)
+	clock.wake(at(7, 59))
+	assert.Equal(t, time.Minute, <-clock.waits)
+	assert.Empty(t, applied)
+	clock.wake(at(8, 0))
+	<-clock.waits
+	assert.Equal(t, "rotation 08:00", <-applied)
+
+	// The rule wins while it is in effect
+	clock.wake(at(9, 0))
+	<-clock.waits
+	assert.Equal(t, "09:00-17:00 work", <-applied)
+	clock.wake(at(10, 0))
+	<-clock.waits
+	assert.Empty(t, applied)
+
+	// Afterwards the rotation's slot is applied again
+	clock.wake(at(17, 0))
+	<-clock.waits
+	assert.Equal(t, "rotation 16:00", <-applied)
+
+	cancel()
+	clock.wake(at(17, 1))
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,237 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+// Boundary is a moment of the day at which a rule starts or ends: a time
+// of the day, or a sun event with an offset.
+type Boundary struct {
+	minutes int // since midnight, for a time of the day
+	event   sunEvent
+	offset  time.Duration // added to the event
+	loc     Location      // where the event is observed
+	text    string
+}
+
+// ParseBoundary parses a time of the day like "07:30", or one of the sun
+// events dawn, sunrise, sunset and dusk with an optional offset like
+// "sunset-30m". Sun events need loc.
+func ParseBoundary(s string, loc *Location) (Boundary, error) {
+	if t, err := time.Parse("15:04", s); err == nil {
+		return Boundary{minutes: t.Hour()*60 + t.Minute(), text: s}, nil
+	}
+	name, offset := s, ""
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		name, offset = s[:i], s[i:]
+	}
+	event, ok := sunEventNames[name]
+	if !ok {
+		return Boundary{}, fmt.Errorf("invalid time %q, expected hh:mm or dawn, sunrise, sunset or dusk", s)
+	}
+	if loc == nil {
+		return Boundary{}, fmt.Errorf("%s needs the latitude and longitude of the [solar] config section", name)
+	}
+	b := Boundary{event: event, loc: *loc, text: s}
+	if offset != "" {
+		d, err := time.ParseDuration(offset)
+		if err != nil {
+			return Boundary{}, fmt.Errorf("invalid offset in %q: %w", s, err)
+		}
+		b.offset = d
+	}
+	return b, nil
+}
+
+// At returns the moment of the boundary on the day of day, in day's location.
+// Where the sun does not set, the morning events are at midnight and the
+// evening events at the following midnight; where it does not rise, all of
+// them are at the solar noon.
+func (b Boundary) At(day time.Time) time.Time {
+	y, m, d := day.Date()
+	if b.event == noSunEvent {
+		return time.Date(y, m, d, b.minutes/60, b.minutes%60, 0, 0, day.Location())
+	}
+	morning, evening, state := sunTimes(day, b.loc, b.event.zenith())
+	isMorning := b.event == dawn || b.event == sunrise
+	var t time.Time
+	switch {
+	case state == sunAlwaysAbove && isMorning:
+		t = time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+	case state == sunAlwaysAbove:
+		t = time.Date(y, m, d+1, 0, 0, 0, 0, day.Location())
+	case isMorning:
+		t = morning.In(day.Location())
+	default:
+		t = evening.In(day.Location())
+	}
+	return t.Add(b.offset)
+}
+
+// nominal returns a typical time of the boundary in minutes since midnight.
+func (b Boundary) nominal() int {
+	if b.event == noSunEvent {
+		return b.minutes
+	}
+	return (b.event.nominal() + int(b.offset.Minutes()) + 24*60) % (24 * 60)
+}
+
+func (b Boundary) String() string {
+	return b.text
+}
+
+// Rule applies a theme from one boundary to the next. Rules whose end comes
+// before their start span midnight.
+type Rule struct {
+	From, To Boundary
+	it.Selector
+}
+
+// FromConfig returns the rules of the [[schedule]] config section followed
+// by the day and night rules of the [solar] section.
+func FromConfig(config cf.Config) ([]Rule, error) {
+	var loc *Location
+	if config.Solar.Latitude != 0 || config.Solar.Longitude != 0 {
+		loc = &Location{Latitude: config.Solar.Latitude, Longitude: config.Solar.Longitude}
+	}
+	rules, err := ParseRules(config.Schedule, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	solar := config.Solar
+	morning, evening := "sunrise", "sunset"
+	if solar.Twilight {
+		morning, evening = "dawn", "dusk"
+	}
+	for _, c := range []struct {
+		name string
+		rule cf.ScheduleRule
+	}{
+		{"day", cf.ScheduleRule{From: morning, To: evening, Theme: solar.DayTheme, Filter: solar.DayFilter}},
+		{"night", cf.ScheduleRule{From: evening, To: morning, Theme: solar.NightTheme, Filter: solar.NightFilter}},
+	} {
+		if c.rule.Theme == "" && c.rule.Filter == "" {
+			continue
+		}
+		r, err := parseRule(c.rule, loc)
+		if err != nil {
+			return nil, fmt.Errorf("solar %s: %w", c.name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// ParseRules checks and parses the rules of the [[schedule]] config section.
+// loc is needed by rules using sun events.
+func ParseRules(config []cf.ScheduleRule, loc *Location) ([]Rule, error) {
+	rules := make([]Rule, len(config))
+	for i, c := range config {
+		var err error
+		if rules[i], err = parseRule(c, loc); err != nil {
+			return nil, fmt.Errorf("schedule rule %d: %w", i+1, err)
+		}
+	}
+	return rules, nil
+}
+
+func parseRule(c cf.ScheduleRule, loc *Location) (Rule, error) {
+	var r Rule
+	var err error
+	if r.From, err = ParseBoundary(c.From, loc); err != nil {
+		return Rule{}, err
+	}
+	if r.To, err = ParseBoundary(c.To, loc); err != nil {
+		return Rule{}, err
+	}
+	if r.From == r.To {
+		return Rule{}, fmt.Errorf("starts and ends at %s", r.From)
+	}
+	if r.Selector, err = it.NewSelector(c.Theme, c.Filter); err != nil {
+		return Rule{}, err
+	}
+	return r, nil
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s-%s %s", r.From, r.To, r.Selector)
+}
+
+// span returns when the rule is in effect if it starts on the day of day.
+// The rule spans midnight if it ends before it starts. Sun events can
+// coincide, e.g. in a polar night sunrise and sunset are both at noon: then
+// the usual order of the boundaries decides, so that the night lasts all
+// day and the day not at all.
+func (r Rule) span(day time.Time) (start, end time.Time) {
+	start, end = r.From.At(day), r.To.At(day)
+	if end.Before(start) || end.Equal(start) && r.To.nominal() <= r.From.nominal() {
+		end = r.To.At(day.AddDate(0, 0, 1))
+	}
+	return start, end
+}
+
+// Active returns the index of the first rule in effect at t.
+func Active(rules []Rule, t time.Time) (int, bool) {
+	for i, r := range rules {
+		// A rule in effect at t started today, or yesterday if it spans midnight
+		for _, day := range []time.Time{t, t.AddDate(0, 0, -1)} {
+			start, end := r.span(day)
+			if !t.Before(start) && t.Before(end) {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// NextBoundary returns the first moment after t at which a rule starts or ends.
+func NextBoundary(rules []Rule, t time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, r := range rules {
+		for _, day := range []time.Time{t.AddDate(0, 0, -1), t, t.AddDate(0, 0, 1)} {
+			start, end := r.span(day)
+			for _, b := range []time.Time{start, end} {
+				if b.After(t) && (next.IsZero() || b.Before(next)) {
+					next = b
+				}
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Switch is a moment at which another rule comes into effect.
+type Switch struct {
+	At   time.Time
+	Rule int // index of the rule, -1 when no rule is in effect anymore
+}
+
+// Switches returns the switches between from and until.
+func Switches(rules []Rule, from, until time.Time) []Switch {
+	var switches []Switch
+	current, ok := Active(rules, from)
+	if !ok {
+		current = -1
+	}
+	for t := from; ; {
+		next, ok := NextBoundary(rules, t)
+		if !ok || next.After(until) {
+			return switches
+		}
+		i, ok := Active(rules, next)
+		if !ok {
+			i = -1
+		}
+		if i != current {
+			switches = append(switches, Switch{At: next, Rule: i})
+			current = i
+		}
+		t = next
+	}
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	cf "goalacritty_themes/config"
+)
+
+// dayAndNight is a schedule with a rule spanning midnight
+var dayAndNight = []cf.ScheduleRule{
+	{From: "07:00", To: "19:30", Theme: "day"},
+	{From: "19:30", To: "07:00", Filter: "dark"},
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, 3, 10, hour, minute, 0, 0, time.UTC)
+}
+
+// TestParseRules tests that malformed rules are rejected
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(dayAndNight, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "07:00-19:30 day", rules[0].String())
+	assert.Equal(t, "19:30-07:00 filter dark", rules[1].String())
+
+	for _, invalid := range []cf.ScheduleRule{
+		{From: "7am", To: "19:00", Theme: "day"},
+		{From: "07:00", To: "24:00", Theme: "day"},
+		{From: "07:00", To: "07:00", Theme: "day"},
+		{From: "07:00", To: "19:00"},
+		{From: "07:00", To: "19:00", Theme: "day", Filter: "light"},
+		{From: "07:00", To: "19:00", Filter: "hue:teal"},
+	} {
+		_, err := ParseRules([]cf.ScheduleRule{invalid}, nil)
+		assert.Error(t, err, "Expected an error for %+v", invalid)
+	}
+}
+
+// TestActive tests finding the rule in effect, including across midnight
+func TestActive(t *testing.T) {
+	rules, err := ParseRules(dayAndNight, nil)
+	assert.NoError(t, err)
+
+	for _, tt := range []struct {
+		t      time.Time
+		active int
+	}{
+		{at(7, 0), 0},
+		{at(12, 0), 0},
+		{at(19, 29), 0},
+		{at(19, 30), 1},
+		{at(23, 59), 1},
+		{at(0, 0), 1},
+		{at(6, 59), 1},
+	} {
+		i, ok := Active(rules, tt.t)
+		assert.True(t, ok)
+		assert.Equal(t, tt.active, i, "Unexpected rule at %s", tt.t.Format("15:04"))
+	}
+
+	rules = rules[:1]
+	_, ok := Active(rules, at(20, 0))
+	assert.False(t, ok, "No rule should be in effect outside the ranges")
+}
+
+// TestNextBoundary tests finding the next switch
+func TestNextBoundary(t *testing.T) {
+	rules, err := ParseRules(dayAndNight, nil)
+	assert.NoError(t, err)
+
+	next, ok := NextBoundary(rules, at(12, 0))
+	assert.True(t, ok)
+	assert.Equal(t, at(19, 30), next)
+	next, _ = NextBoundary(rules, at(19, 30))
+	assert.Equal(t, at(7, 0).AddDate(0, 0, 1), next)
+	next, _ = NextBoundary(rules, at(3, 0))
+	assert.Equal(t, at(7, 0), next)
+}
+
+// fakeClock is a Clock whose time only changes when the test wakes the daemon.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	timer chan time.Time
+	waits chan time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.mu.Lock()
+	c.timer = ch
+	c.mu.Unlock()
+	c.waits <- d
+	return ch
+}
+
+// wake sets the time and fires the pending timer, like the system does when
+// the timer expires or the machine resumes.
+func (c *fakeClock) wake(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	timer := c.timer
+	c.mu.Unlock()
+	timer <- t
+}
+
+// TestDaemon tests that the daemon switches at the boundaries and recovers
+// after the clock jumped
+func TestDaemon(t *testing.T) {
+	rules, err := ParseRules(dayAndNight, nil)
+	assert.NoError(t, err)
+	clock := &fakeClock{now: at(19, 28), waits: make(chan time.Duration)}
+	applied := make(chan string, 10)
+	var logs []string
+	d := &Daemon{
+		Clock: clock,
+		Rules: rules,
+		Apply: func(r Rule) error {
+			applied <- r.String()
+			return nil
+		},
+		Logf: func(format string, a ...any) { logs = append(logs, format) },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+
+	// The rule in effect is applied right away
+	assert.Equal(t, CheckInterval, <-clock.waits)
+	assert.Equal(t, "07:00-19:30 day", <-applied)
+
+	// Nothing changes within a rule
+	clock.wake(at(19, 29))
+	assert.Equal(t, time.Minute, <-clock.waits)
+	assert.Empty(t, applied)
+
+	// The next rule is applied at the boundary
+	clock.wake(at(19, 30))
+	<-clock.waits
+	assert.Equal(t, "19:30-07:00 filter dark", <-applied)
+
+	// After a suspend the rule in effect now is applied
+	clock.wake(at(12, 0).AddDate(0, 0, 1))
+	<-clock.waits
+	assert.Equal(t, "07:00-19:30 day", <-applied)
+	assert.Contains(t, logs, "the clock jumped by %s, checking the schedule")
+
+	cancel()
+	clock.wake(at(12, 1).AddDate(0, 0, 1))
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+// TestSwitches tests listing the upcoming switches
+func TestSwitches(t *testing.T) {
+	rules, err := ParseRules(dayAndNight[:1], nil)
+	assert.NoError(t, err)
+
+	switches := Switches(rules, at(12, 0), at(12, 0).AddDate(0, 0, 1))
+	assert.Equal(t, []Switch{
+		{At: at(19, 30), Rule: -1},
+		{At: at(7, 0).AddDate(0, 0, 1), Rule: 0},
+	}, switches)
+}
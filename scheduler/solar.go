@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+)
+
+// Location is where the sun is observed from, in degrees. North and east
+// are positive.
+type Location struct {
+	Latitude, Longitude float64
+}
+
+// sunEvent is a moment of the day defined by the position of the sun.
+type sunEvent int
+
+const (
+	noSunEvent sunEvent = iota
+	dawn                // start of civil twilight
+	sunrise
+	sunset
+	dusk // end of civil twilight
+)
+
+var sunEventNames = map[string]sunEvent{"dawn": dawn, "sunrise": sunrise, "sunset": sunset, "dusk": dusk}
+
+func (e sunEvent) String() string {
+	for name, event := range sunEventNames {
+		if event == e {
+			return name
+		}
+	}
+	return ""
+}
+
+// zenith returns the angle between the sun and the zenith at the event: the
+// sun's disc touching the horizon, refraction included, or 6° below it.
+func (e sunEvent) zenith() float64 {
+	if e == dawn || e == dusk {
+		return 96
+	}
+	return 90.833
+}
+
+// nominal returns a typical time of the event in minutes since midnight.
+// It orders rules whose boundaries coincide, as they do in polar days.
+func (e sunEvent) nominal() int {
+	return map[sunEvent]int{dawn: 330, sunrise: 360, sunset: 1080, dusk: 1110}[e]
+}
+
+func rad(deg float64) float64 { return deg * math.Pi / 180 }
+func deg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// sunState tells whether the sun crosses a zenith angle during a day.
+type sunState int
+
+const (
+	sunCrosses sunState = iota
+	sunAlwaysAbove
+	sunAlwaysBelow
+)
+
+// sunTimes returns the moments the sun crosses zenith in the morning and in
+// the evening of the day of date, in UTC. It follows the NOAA solar
+// calculator. When the sun does not cross zenith, both moments are the
+// solar noon.
+func sunTimes(date time.Time, loc Location, zenith float64) (morning, evening time.Time, state sunState) {
+	y, m, d := date.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	// Evaluate the sun's position around the local solar noon
+	jd := float64(midnight.Unix())/86400 + 2440587.5 + 0.5 - loc.Longitude/360
+	jc := (jd - 2451545) / 36525
+
+	meanLong := math.Mod(280.46646+jc*(36000.76983+jc*0.0003032), 360)
+	meanAnom := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	eccent := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+	center := math.Sin(rad(meanAnom))*(1.914602-jc*(0.004817+0.000014*jc)) +
+		math.Sin(rad(2*meanAnom))*(0.019993-0.000101*jc) +
+		math.Sin(rad(3*meanAnom))*0.000289
+	omega := 125.04 - 1934.136*jc
+	appLong := meanLong + center - 0.00569 - 0.00478*math.Sin(rad(omega))
+	meanObliq := 23 + (26+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60)/60
+	obliq := meanObliq + 0.00256*math.Cos(rad(omega))
+	declination := math.Asin(math.Sin(rad(obliq)) * math.Sin(rad(appLong)))
+
+	yy := math.Pow(math.Tan(rad(obliq/2)), 2)
+	eqTime := 4 * deg(yy*math.Sin(2*rad(meanLong))-
+		2*eccent*math.Sin(rad(meanAnom))+
+		4*eccent*yy*math.Sin(rad(meanAnom))*math.Cos(2*rad(meanLong))-
+		0.5*yy*yy*math.Sin(4*rad(meanLong))-
+		1.25*eccent*eccent*math.Sin(2*rad(meanAnom)))
+	noon := 720 - 4*loc.Longitude - eqTime // minutes after midnight UTC
+
+	cosHourAngle := math.Cos(rad(zenith))/(math.Cos(rad(loc.Latitude))*math.Cos(declination)) -
+		math.Tan(rad(loc.Latitude))*math.Tan(declination)
+	minutes := func(m float64) time.Time {
+		return midnight.Add(time.Duration(m * float64(time.Minute))).Round(time.Second)
+	}
+	switch {
+	case cosHourAngle < -1:
+		return minutes(noon), minutes(noon), sunAlwaysAbove
+	case cosHourAngle > 1:
+		return minutes(noon), minutes(noon), sunAlwaysBelow
+	}
+	hourAngle := deg(math.Acos(cosHourAngle))
+	return minutes(noon - 4*hourAngle), minutes(noon + 4*hourAngle), sunCrosses
+}
@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	cf "goalacritty_themes/config"
+)
+
+var (
+	berlin = Location{Latitude: 52.52, Longitude: 13.405}
+	tromso = Location{Latitude: 69.65, Longitude: 18.96}
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+	return loc
+}
+
+// TestSunTimes tests the sun events against published times
+func TestSunTimes(t *testing.T) {
+	tz := mustLoad(t, "Europe/Berlin")
+	day := time.Date(2024, 6, 21, 12, 0, 0, 0, tz)
+
+	for _, tt := range []struct {
+		event    string
+		expected string
+	}{
+		{"dawn", "03:52"},
+		{"sunrise", "04:43"},
+		{"sunset", "21:33"},
+		{"dusk", "22:24"},
+	} {
+		b, err := ParseBoundary(tt.event, &berlin)
+		assert.NoError(t, err)
+		expected, _ := time.ParseInLocation("2006-01-02 15:04", "2024-06-21 "+tt.expected, tz)
+		assert.WithinDuration(t, expected, b.At(day), 2*time.Minute, "Unexpected %s", tt.event)
+	}
+}
+
+// TestParseBoundaryOffsets tests offsets from sun events
+func TestParseBoundaryOffsets(t *testing.T) {
+	day := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	sunset, err := ParseBoundary("sunset", &berlin)
+	assert.NoError(t, err)
+	early, err := ParseBoundary("sunset-1h30m", &berlin)
+	assert.NoError(t, err)
+	assert.Equal(t, "sunset-1h30m", early.String())
+	assert.Equal(t, sunset.At(day).Add(-90*time.Minute), early.At(day))
+
+	for _, invalid := range []string{"moonrise", "sunset+1x", "sunset+"} {
+		_, err := ParseBoundary(invalid, &berlin)
+		assert.Error(t, err, "Expected an error for %q", invalid)
+	}
+	_, err = ParseBoundary("sunset", nil)
+	assert.Error(t, err, "Sun events should need a location")
+}
+
+// TestPolarDayAndNight tests that the night rule lasts all day in a polar
+// night, and the day rule in a polar day
+func TestPolarDayAndNight(t *testing.T) {
+	config := cf.Config{}
+	config.Solar.Latitude, config.Solar.Longitude = tromso.Latitude, tromso.Longitude
+	config.Solar.DayTheme, config.Solar.NightTheme = "day", "night"
+	rules, err := FromConfig(config)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	for _, tt := range []struct {
+		date     time.Time
+		expected string
+	}{
+		{time.Date(2024, 6, 21, 0, 30, 0, 0, time.UTC), "day"},
+		{time.Date(2024, 6, 21, 23, 30, 0, 0, time.UTC), "day"},
+		{time.Date(2024, 12, 21, 11, 0, 0, 0, time.UTC), "night"},
+		{time.Date(2024, 12, 21, 23, 0, 0, 0, time.UTC), "night"},
+		{time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC), "day"},
+		{time.Date(2024, 3, 20, 22, 0, 0, 0, time.UTC), "night"},
+	} {
+		i, ok := Active(rules, tt.date)
+		assert.True(t, ok, "No rule in effect at %s", tt.date)
+		assert.Equal(t, tt.expected, rules[i].Theme, "Unexpected rule at %s", tt.date)
+	}
+}
+
+// TestFromConfig tests combining the schedule with the solar rules
+func TestFromConfig(t *testing.T) {
+	config := cf.Config{Schedule: []cf.ScheduleRule{{From: "dusk", To: "23:00", Filter: "dark"}}}
+	_, err := FromConfig(config)
+	assert.Error(t, err, "Sun events should need a location")
+
+	config.Solar.Latitude, config.Solar.Longitude = berlin.Latitude, berlin.Longitude
+	config.Solar.Twilight = true
+	config.Solar.DayFilter = "light"
+	rules, err := FromConfig(config)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+	assert.Equal(t, "dawn-dusk filter light", rules[1].String())
+}
@@ -0,0 +1,115 @@
+// Package sshhost matches the destination of an ssh command line against
+// the [[ssh_hosts]] rules of the config.
+package sshhost
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	cf "goalacritty_themes/config"
+	it "goalacritty_themes/theme_tools"
+)
+
+// Rule applies a theme to the hosts matching a glob pattern. Patterns with
+// an @ match user@host, the others only the host.
+type Rule struct {
+	Pattern string
+	it.Selector
+}
+
+// ParseRules checks and parses the rules of the [[ssh_hosts]] config section.
+func ParseRules(config []cf.SSHHost) ([]Rule, error) {
+	rules := make([]Rule, len(config))
+	for i, c := range config {
+		if _, err := path.Match(c.Pattern, ""); err != nil || c.Pattern == "" {
+			return nil, fmt.Errorf("ssh host %d: invalid pattern %q", i+1, c.Pattern)
+		}
+		sel, err := it.NewSelector(c.Theme, c.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("ssh host %s: %w", c.Pattern, err)
+		}
+		rules[i] = Rule{Pattern: c.Pattern, Selector: sel}
+	}
+	return rules, nil
+}
+
+// Match returns the first rule matching the destination.
+func Match(rules []Rule, user, host string) (Rule, bool) {
+	for _, r := range rules {
+		name := host
+		if strings.Contains(r.Pattern, "@") {
+			name = user + "@" + host
+		}
+		if ok, _ := path.Match(r.Pattern, name); ok {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// optionsWithArgument are the options of ssh which take an argument.
+const optionsWithArgument = "BbcDEeFIiJLlmOopQRSWw"
+
+// Destination returns the user and host ssh connects to with args, as far
+// as the command line tells: aliases of the ssh config are not resolved,
+// and user is empty if the command line names none.
+func Destination(args []string) (user, host string, ok bool) {
+	var loginName string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") || arg == "-" {
+			if arg == "--" {
+				if i++; i == len(args) {
+					break
+				}
+			}
+			if ok {
+				// The remote command starts here
+				break
+			}
+			user, host = splitDestination(args[i])
+			if ok = host != ""; !ok {
+				break
+			}
+			// ssh reads options after the destination too
+			continue
+		}
+		// Options may be grouped, and their argument attached or not
+		for j := 1; j < len(arg); j++ {
+			if !strings.ContainsRune(optionsWithArgument, rune(arg[j])) {
+				continue
+			}
+			value := arg[j+1:]
+			if value == "" && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			if arg[j] == 'l' {
+				loginName = value
+			}
+			break
+		}
+	}
+	if loginName != "" {
+		// ssh prefers -l to the user of the destination
+		user = loginName
+	}
+	return user, host, ok
+}
+
+// splitDestination splits [user@]host or ssh://[user@]host[:port].
+func splitDestination(dest string) (user, host string) {
+	if strings.HasPrefix(dest, "ssh://") {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return "", ""
+		}
+		return u.User.Username(), u.Hostname()
+	}
+	if i := strings.LastIndex(dest, "@"); i >= 0 {
+		return dest[:i], dest[i+1:]
+	}
+	return "", dest
+}
@@ -0,0 +1,66 @@
+package sshhost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	cf "goalacritty_themes/config"
+)
+
+// TestDestination tests finding the destination among ssh's options
+func TestDestination(t *testing.T) {
+	tests := []struct {
+		args       []string
+		user, host string
+	}{
+		{[]string{"db1.prod"}, "", "db1.prod"},
+		{[]string{"root@db1.prod", "uptime"}, "root", "db1.prod"},
+		{[]string{"-p", "2222", "-A", "deploy@web"}, "deploy", "web"},
+		{[]string{"-vp2222", "web"}, "", "web"},
+		{[]string{"-o", "StrictHostKeyChecking=no", "-i", "~/.ssh/id", "web", "-l", "admin", "ls", "-l", "x"}, "admin", "web"},
+		{[]string{"-l", "admin", "root@web"}, "admin", "web"},
+		{[]string{"ssh://ops@[2001:db8::1]:2222"}, "ops", "2001:db8::1"},
+		{[]string{"-4", "--", "web", "ls"}, "", "web"},
+	}
+	for _, test := range tests {
+		user, host, ok := Destination(test.args)
+		assert.True(t, ok, "%q", test.args)
+		assert.Equal(t, test.user, user, "%q", test.args)
+		assert.Equal(t, test.host, host, "%q", test.args)
+	}
+
+	for _, args := range [][]string{nil, {"-V"}, {"-p", "22"}, {"--"}} {
+		_, _, ok := Destination(args)
+		assert.False(t, ok, "%q", args)
+	}
+}
+
+// TestMatch tests matching hosts and users against the rules
+func TestMatch(t *testing.T) {
+	rules, err := ParseRules([]cf.SSHHost{
+		{Pattern: "root@*", Theme: "red_alert"},
+		{Pattern: "*.prod.example.com", Filter: "dark red"},
+		{Pattern: "staging-?", Theme: "solarized_light"},
+	})
+	assert.NoError(t, err)
+
+	r, ok := Match(rules, "root", "scratch")
+	assert.True(t, ok)
+	assert.Equal(t, "red_alert", r.Theme)
+	r, ok = Match(rules, "deploy", "db1.prod.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "dark red", r.Filter)
+	r, ok = Match(rules, "", "staging-2")
+	assert.True(t, ok)
+	assert.Equal(t, "solarized_light", r.Theme)
+
+	_, ok = Match(rules, "deploy", "prod.example.com.evil")
+	assert.False(t, ok)
+	_, ok = Match(rules, "", "staging-12")
+	assert.False(t, ok)
+
+	for _, invalid := range []cf.SSHHost{{Pattern: "[a-", Theme: "nord"}, {Theme: "nord"}, {Pattern: "*"}} {
+		_, err := ParseRules([]cf.SSHHost{invalid})
+		assert.Error(t, err, "Expected an error for %+v", invalid)
+	}
+}
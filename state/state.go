@@ -0,0 +1,183 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// maxRecent is the number of recently used themes which are remembered.
+const maxRecent = 20
+
+// RecentEntry records when a theme was last applied.
+type RecentEntry struct {
+	Theme  string    `json:"theme"`
+	UsedAt time.Time `json:"used_at"`
+}
+
+// RotationState is the theme the rotation chose for the slot starting at Slot.
+type RotationState struct {
+	Theme string    `json:"theme"`
+	Slot  time.Time `json:"slot"`
+}
+
+// Store holds what the user told us about themes: favorites, tags and the
+// recently used themes. Themes are identified by name.
+type Store struct {
+	Favorites []string            `json:"favorites"`
+	Tags      map[string][]string `json:"tags"`
+	Recent    []RecentEntry       `json:"recent"`
+	Rotation  RotationState       `json:"rotation"`
+
+	path string
+}
+
+// Path returns where the store is kept: goalacritty/state.json in
+// $XDG_STATE_HOME, or in ~/.local/state if it is not set.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "goalacritty", "state.json"), nil
+}
+
+// Load opens the store at the default path.
+func Load() (*Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path)
+}
+
+// Open reads the store at path. A missing file gives an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		s.Tags = map[string][]string{}
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Tags == nil {
+		s.Tags = map[string][]string{}
+	}
+	return s, nil
+}
+
+// Save writes the store back to disk.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// IsFavorite reports whether theme is a favorite.
+func (s *Store) IsFavorite(theme string) bool {
+	return slices.Contains(s.Favorites, theme)
+}
+
+// AddFavorite adds theme to the favorites. It reports false if it already was one.
+func (s *Store) AddFavorite(theme string) bool {
+	if s.IsFavorite(theme) {
+		return false
+	}
+	s.Favorites = append(s.Favorites, theme)
+	return true
+}
+
+// RemoveFavorite removes theme from the favorites. It reports false if it was not one.
+func (s *Store) RemoveFavorite(theme string) bool {
+	i := slices.Index(s.Favorites, theme)
+	if i < 0 {
+		return false
+	}
+	s.Favorites = slices.Delete(s.Favorites, i, i+1)
+	return true
+}
+
+// ToggleFavorite adds or removes theme and reports whether it is a favorite now.
+func (s *Store) ToggleFavorite(theme string) bool {
+	if s.RemoveFavorite(theme) {
+		return false
+	}
+	return s.AddFavorite(theme)
+}
+
+// AddTags tags theme.
+func (s *Store) AddTags(theme string, tags ...string) {
+	for _, tag := range tags {
+		if !slices.Contains(s.Tags[theme], tag) {
+			s.Tags[theme] = append(s.Tags[theme], tag)
+		}
+	}
+	sort.Strings(s.Tags[theme])
+}
+
+// RemoveTags removes tags from theme.
+func (s *Store) RemoveTags(theme string, tags ...string) {
+	s.Tags[theme] = slices.DeleteFunc(s.Tags[theme], func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+	if len(s.Tags[theme]) == 0 {
+		delete(s.Tags, theme)
+	}
+}
+
+// TagsOf returns the tags of theme.
+func (s *Store) TagsOf(theme string) []string {
+	return s.Tags[theme]
+}
+
+// Tagged returns the themes tagged with tag, sorted by name.
+func (s *Store) Tagged(tag string) []string {
+	var themes []string
+	for theme, tags := range s.Tags {
+		if slices.Contains(tags, tag) {
+			themes = append(themes, theme)
+		}
+	}
+	sort.Strings(themes)
+	return themes
+}
+
+// Touch records that theme was applied at the given time. The most recently
+// used theme comes first.
+func (s *Store) Touch(theme string, at time.Time) {
+	s.Recent = slices.DeleteFunc(s.Recent, func(e RecentEntry) bool { return e.Theme == theme })
+	s.Recent = slices.Insert(s.Recent, 0, RecentEntry{Theme: theme, UsedAt: at})
+	if len(s.Recent) > maxRecent {
+		s.Recent = s.Recent[:maxRecent]
+	}
+}
+
+// RecentThemes returns the names of the recently used themes, most recent first.
+func (s *Store) RecentThemes() []string {
+	themes := make([]string, len(s.Recent))
+	for i, e := range s.Recent {
+		themes[i] = e.Theme
+	}
+	return themes
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOpenMissing tests that a missing state file gives an empty store
+func TestOpenMissing(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, s.Favorites)
+	assert.Empty(t, s.TagsOf("dracula"))
+	assert.Empty(t, s.RecentThemes())
+}
+
+// TestOpenCorrupt tests that a corrupt state file is reported rather than overwritten
+func TestOpenCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err := Open(path)
+	assert.Error(t, err)
+}
+
+// TestFavorites tests adding, removing and toggling favorites
+func TestFavorites(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+
+	assert.True(t, s.AddFavorite("dracula"))
+	assert.False(t, s.AddFavorite("dracula"), "Adding twice should be a no-op")
+	assert.True(t, s.ToggleFavorite("nord"))
+	assert.Equal(t, []string{"dracula", "nord"}, s.Favorites)
+	assert.False(t, s.ToggleFavorite("dracula"))
+	assert.False(t, s.RemoveFavorite("dracula"))
+	assert.Equal(t, []string{"nord"}, s.Favorites)
+}
+
+// TestTags tests tagging themes and looking themes up by tag
+func TestTags(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+
+	s.AddTags("nord", "work", "calm", "work")
+	s.AddTags("dracula", "work")
+	assert.Equal(t, []string{"calm", "work"}, s.TagsOf("nord"))
+	assert.Equal(t, []string{"dracula", "nord"}, s.Tagged("work"))
+
+	s.RemoveTags("nord", "work", "calm")
+	assert.Empty(t, s.TagsOf("nord"))
+	assert.Equal(t, []string{"dracula"}, s.Tagged("work"))
+}
+
+// TestRecent tests that recently used themes are deduplicated and capped
+func TestRecent(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < maxRecent+5; i++ {
+		s.Touch(fmt.Sprintf("theme%d", i), start.Add(time.Duration(i)*time.Minute))
+	}
+	s.Touch("theme10", start.Add(time.Hour))
+	recent := s.RecentThemes()
+	assert.Len(t, recent, maxRecent)
+	assert.Equal(t, []string{"theme10", "theme24", "theme23"}, recent[:3])
+	assert.Equal(t, start.Add(time.Hour), s.Recent[0].UsedAt)
+}
+
+// TestSave tests that the store survives a round trip through the disk
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goalacritty", "state.json")
+	s, err := Open(path)
+	assert.NoError(t, err)
+	s.AddFavorite("nord")
+	s.AddTags("nord", "work")
+	s.Touch("nord", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, s.Save())
+
+	loaded, err := Open(path)
+	assert.NoError(t, err)
+	assert.Equal(t, s.Favorites, loaded.Favorites)
+	assert.Equal(t, s.Tags, loaded.Tags)
+	assert.True(t, s.Recent[0].UsedAt.Equal(loaded.Recent[0].UsedAt))
+}
+
+// TestPath tests that XDG_STATE_HOME is honoured
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	path, err := Path()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/state/goalacritty/state.json", path)
+}
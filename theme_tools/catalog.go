@@ -0,0 +1,184 @@
+package install_themes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"goalacritty_themes/palette"
+	"golang.org/x/sync/errgroup"
+)
+
+// catalogVersion must be bumped whenever the stored entries or the way the
+// derived metrics are computed change, so that old catalogs are rebuilt.
+const catalogVersion = 1
+
+// catalogEntry is what the catalog remembers about one theme file.
+type catalogEntry struct {
+	Size            int64            `json:"size"`
+	ModTime         time.Time        `json:"mod_time"`
+	Hash            string           `json:"hash"`
+	Palette         *palette.Palette `json:"palette,omitempty"`
+	Class           palette.Class    `json:"class"`
+	ClassConfidence float64          `json:"class_confidence"`
+	Readability     float64          `json:"readability"`
+}
+
+type catalogFile struct {
+	Version int                     `json:"version"`
+	Entries map[string]catalogEntry `json:"entries"`
+}
+
+// Catalog is a persistent index of parsed theme files, so that palettes are
+// only parsed again when a file changes. It is stored as JSON in the cache
+// directory and is purely an optimisation: a missing or unreadable catalog is
+// rebuilt from the theme files.
+type Catalog struct {
+	path    string
+	entries map[string]catalogEntry
+	parsed  int // number of files parsed by the last Refresh
+}
+
+// CatalogPath returns where the catalog is stored: goalacritty/catalog.json
+// in $XDG_CACHE_HOME, or in the platform's cache directory if it is not set.
+func CatalogPath() (string, error) {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		var err error
+		if dir, err = os.UserCacheDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "goalacritty", "catalog.json"), nil
+}
+
+// OpenCatalog loads the catalog stored at path. An empty catalog is returned
+// if there is none yet or it cannot be used.
+func OpenCatalog(path string) *Catalog {
+	c := &Catalog{path: path, entries: map[string]catalogEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var stored catalogFile
+	if json.Unmarshal(data, &stored) == nil && stored.Version == catalogVersion && stored.Entries != nil {
+		c.entries = stored.Entries
+	}
+	return c
+}
+
+// Save writes the catalog back to disk.
+func (c *Catalog) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(catalogFile{Version: catalogVersion, Entries: c.entries})
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that concurrent runs never see a
+	// half written catalog
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), "catalog-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// Refresh brings the entries of themes up to date and copies their palettes
+// and metrics into themes. Files whose size and modification time did not
+// change are not read at all; the others are read and parsed in parallel.
+// Entries of files which are not in themes any more are dropped.
+func (c *Catalog) Refresh(themes []ThemeData) {
+	fresh := make([]catalogEntry, len(themes))
+	var mu sync.Mutex
+	c.parsed = 0
+
+	var g errgroup.Group
+	g.SetLimit(runtime.NumCPU())
+	for i := range themes {
+		path := themes[i].FullPath
+		old, known := c.entries[path]
+		g.Go(func() error {
+			info, err := os.Stat(path)
+			if err != nil {
+				// The theme stays unparsed, as if the file was broken
+				return nil
+			}
+			if known && info.Size() == old.Size && info.ModTime().Equal(old.ModTime) {
+				fresh[i] = old
+				return nil
+			}
+			fresh[i] = indexThemeFile(path, info, old)
+			mu.Lock()
+			c.parsed++
+			mu.Unlock()
+			return nil
+		})
+	}
+	g.Wait()
+
+	c.entries = make(map[string]catalogEntry, len(themes))
+	for i := range themes {
+		if fresh[i].Hash == "" {
+			continue
+		}
+		c.entries[themes[i].FullPath] = fresh[i]
+		themes[i].Palette = fresh[i].Palette
+		themes[i].Class = fresh[i].Class
+		themes[i].ClassConfidence = fresh[i].ClassConfidence
+		themes[i].Readability = fresh[i].Readability
+	}
+}
+
+// indexThemeFile reads and parses a theme file. If only its modification
+// time changed, the previous entry's palette is kept.
+func indexThemeFile(path string, info os.FileInfo, old catalogEntry) catalogEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return catalogEntry{}
+	}
+	sum := sha256.Sum256(data)
+	entry := catalogEntry{Size: info.Size(), ModTime: info.ModTime(), Hash: hex.EncodeToString(sum[:])}
+	if entry.Hash == old.Hash {
+		entry.Palette, entry.Class, entry.ClassConfidence, entry.Readability = old.Palette, old.Class, old.ClassConfidence, old.Readability
+		return entry
+	}
+	p, err := palette.Parse(data)
+	if err != nil {
+		return entry
+	}
+	entry.Palette = p
+	entry.Class, entry.ClassConfidence = p.Classify()
+	entry.Readability = p.Audit().Score
+	return entry
+}
+
+// loadPalettes fills in the palettes of themes using the catalog in the
+// cache directory, updating it as needed. Failing to store the catalog only
+// costs time on the next run, so such errors are ignored.
+func loadPalettes(themes []ThemeData) {
+	path, err := CatalogPath()
+	if err != nil {
+		// Without a cache directory every theme is parsed each time
+		path = ""
+	}
+	c := OpenCatalog(path)
+	c.Refresh(themes)
+	if path != "" {
+		c.Save()
+	}
+}
@@ -0,0 +1,125 @@
+package install_themes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	configloader "goalacritty_themes/config"
+	"goalacritty_themes/palette"
+)
+
+// TestMain keeps the catalog written by GetThemeDataNames out of the user's cache directory
+func TestMain(m *testing.M) {
+	cacheDir, err := os.MkdirTemp("", "test_catalog_cache")
+	if err != nil {
+		fmt.Println("Error creating cache directory:", err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+	code := m.Run()
+	os.RemoveAll(cacheDir)
+	os.Exit(code)
+}
+
+// catalogThemes writes count themes and returns them as listed by readThemeDir
+func catalogThemes(t testing.TB, dir string, count int) []ThemeData {
+	for i := 0; i < count; i++ {
+		content := fmt.Sprintf("[colors.primary]\nbackground = '#%02x%02x%02x'\nforeground = '#cdd6f4'\n", i%256, (i/256)%256, 0x2e)
+		err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("theme_%05d.toml", i)), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Error writing theme: %v", err)
+		}
+	}
+	themes, err := readThemeDir(dir, DefaultSource)
+	if err != nil {
+		t.Fatalf("Error reading themes: %v", err)
+	}
+	return themes
+}
+
+// TestCatalogRefresh tests that only changed theme files are parsed again
+func TestCatalogRefresh(t *testing.T) {
+	dir := t.TempDir()
+	catalogPath := filepath.Join(t.TempDir(), "catalog.json")
+	themes := catalogThemes(t, dir, 5)
+
+	c := OpenCatalog(catalogPath)
+	c.Refresh(themes)
+	assert.Equal(t, 5, c.parsed, "Expected every theme to be parsed on the first run")
+	assert.Equal(t, palette.Dark, themes[0].Class)
+	assert.NotZero(t, themes[0].Readability)
+	assert.NoError(t, c.Save())
+
+	// A fresh process reads the stored catalog and parses nothing
+	themes, err := readThemeDir(dir, DefaultSource)
+	assert.NoError(t, err)
+	c = OpenCatalog(catalogPath)
+	c.Refresh(themes)
+	assert.Equal(t, 0, c.parsed)
+	assert.Equal(t, "#00002e", themes[0].Palette.Background.Hex(), "Expected the palette to come from the catalog")
+
+	// Touching a file makes it be read again, changing it makes it be parsed again
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(themes[1].FullPath, later, later))
+	assert.NoError(t, os.WriteFile(themes[2].FullPath, []byte("[colors.primary]\nbackground = '#ffffff'\nforeground = '#000000'\n"), 0644))
+	assert.NoError(t, os.Chtimes(themes[2].FullPath, later, later))
+	c.Refresh(themes)
+	assert.Equal(t, 2, c.parsed)
+	assert.Equal(t, palette.Light, themes[2].Class)
+
+	// Removed files are dropped from the catalog
+	assert.NoError(t, os.Remove(themes[4].FullPath))
+	c.Refresh(themes[:4])
+	assert.Len(t, c.entries, 4)
+}
+
+// TestCatalogVersion tests that catalogs of another version are ignored
+func TestCatalogVersion(t *testing.T) {
+	catalogPath := filepath.Join(t.TempDir(), "catalog.json")
+	err := os.WriteFile(catalogPath, []byte(`{"version": 0, "entries": {"/a.toml": {"hash": "x"}}}`), 0644)
+	assert.NoError(t, err)
+	assert.Empty(t, OpenCatalog(catalogPath).entries)
+
+	err = os.WriteFile(catalogPath, []byte(`not json`), 0644)
+	assert.NoError(t, err)
+	assert.Empty(t, OpenCatalog(catalogPath).entries)
+}
+
+// benchmarkCatalog measures GetThemeDataNames over a synthetic catalog of 5000 themes
+func benchmarkCatalog(b *testing.B, warm bool) {
+	themesDir := b.TempDir()
+	assert.NoError(b, os.Mkdir(filepath.Join(themesDir, "themes"), os.ModePerm))
+	catalogThemes(b, filepath.Join(themesDir, "themes"), 5000)
+	b.Setenv("XDG_CACHE_HOME", b.TempDir())
+
+	mockConfig := configloader.Config{}
+	mockConfig.Paths.ThemesDirectory = themesDir
+	if warm {
+		if _, err := GetThemeDataNames(mockConfig); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !warm {
+			b.StopTimer()
+			path, _ := CatalogPath()
+			os.Remove(path)
+			b.StartTimer()
+		}
+		if _, err := GetThemeDataNames(mockConfig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetThemeDataNamesCold parses every theme file
+func BenchmarkGetThemeDataNamesCold(b *testing.B) { benchmarkCatalog(b, false) }
+
+// BenchmarkGetThemeDataNamesWarm reads every palette from the catalog
+func BenchmarkGetThemeDataNamesWarm(b *testing.B) { benchmarkCatalog(b, true) }
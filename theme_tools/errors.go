@@ -0,0 +1,40 @@
+package install_themes
+
+import (
+	"errors"
+	"os"
+)
+
+// Errors returned by the theme tools. They are wrapped with additional
+// context, so callers should compare them using errors.Is.
+var (
+	// ErrConfigNotFound means the alacritty config file does not exist.
+	ErrConfigNotFound = errors.New("alacritty config file not found")
+	// ErrThemeMissing means a theme file referenced by name or by the
+	// alacritty config is not present on disk.
+	ErrThemeMissing = errors.New("theme file is missing")
+	// ErrRepoCorrupt means the themes repository exists but cannot be used,
+	// e.g. its themes directory is gone or empty.
+	ErrRepoCorrupt = errors.New("themes repository is corrupt")
+)
+
+// CheckTheme reports ErrThemeMissing if the theme file does not exist.
+func CheckTheme(theme ThemeData) error {
+	if _, err := os.Stat(theme.FullPath); err != nil {
+		if os.IsNotExist(err) {
+			return &ThemeError{Theme: theme.Name, Err: ErrThemeMissing}
+		}
+		return err
+	}
+	return nil
+}
+
+// ThemeError attaches the name of the offending theme to one of the errors above.
+type ThemeError struct {
+	Theme string
+	Err   error
+}
+
+func (e *ThemeError) Error() string { return e.Theme + ": " + e.Err.Error() }
+
+func (e *ThemeError) Unwrap() error { return e.Err }
@@ -0,0 +1,186 @@
+package install_themes
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"goalacritty_themes/palette"
+)
+
+// defaultColorTolerance is the DeltaE used by bg: and fg: without ~N.
+const defaultColorTolerance = 3
+
+var (
+	colorTermRe    = regexp.MustCompile(`^(bg|fg):#?([0-9a-fA-F]{6})(?:~([0-9]*\.?[0-9]+))?$`)
+	contrastTermRe = regexp.MustCompile(`^contrast(<=|>=|<|>|=)([0-9]*\.?[0-9]+)$`)
+)
+
+// Query is a parsed theme filter. Words are matched against theme names,
+// while these terms match palette attributes:
+//
+//	bg:#1e1e2e~10  background within DeltaE 10 of the color (3 if ~N is omitted)
+//	fg:#cdd6f4~5   the same for the foreground
+//	hue:blue       hue of the background, one of palette.HueNames
+//	contrast>7     WCAG contrast of foreground and background; also <, >=, <= and =
+//	dark, light    dark/light class
+//	source:work    themes of a source (DefaultSource for the repository)
+//
+// All terms must match.
+type Query struct {
+	// Name holds the words to be matched against theme names
+	Name  string
+	terms []queryTerm
+}
+
+type queryTerm func(e *indexEntry) bool
+
+// ParseQuery parses a filter string.
+func ParseQuery(s string) (Query, error) {
+	var q Query
+	var words []string
+	for _, field := range strings.Fields(s) {
+		term, err := parseTerm(strings.ToLower(field))
+		if err != nil {
+			return Query{}, err
+		}
+		if term == nil {
+			words = append(words, field)
+			continue
+		}
+		q.terms = append(q.terms, term)
+	}
+	q.Name = strings.Join(words, " ")
+	return q, nil
+}
+
+// parseTerm parses a single palette term. It returns nil for name words.
+func parseTerm(field string) (queryTerm, error) {
+	if class, ok := palette.ParseClass(field); ok {
+		return func(e *indexEntry) bool { return e.theme.Class == class }, nil
+	}
+	if m := colorTermRe.FindStringSubmatch(field); m != nil {
+		target, _ := palette.ParseColor("#" + m[2])
+		tolerance := float64(defaultColorTolerance)
+		if m[3] != "" {
+			tolerance, _ = strconv.ParseFloat(m[3], 64)
+		}
+		background := m[1] == "bg"
+		return func(e *indexEntry) bool {
+			if e.theme.Palette == nil {
+				return false
+			}
+			c := e.theme.Palette.Foreground
+			if background {
+				c = e.theme.Palette.Background
+			}
+			return palette.DeltaE(c, target) <= tolerance
+		}, nil
+	}
+	if m := contrastTermRe.FindStringSubmatch(field); m != nil {
+		limit, _ := strconv.ParseFloat(m[2], 64)
+		op := m[1]
+		return func(e *indexEntry) bool {
+			if e.theme.Palette == nil {
+				return false
+			}
+			switch op {
+			case "<":
+				return e.contrast < limit
+			case "<=":
+				return e.contrast <= limit
+			case ">":
+				return e.contrast > limit
+			case ">=":
+				return e.contrast >= limit
+			}
+			// Contrast ratios are usually written with one decimal
+			return fmt.Sprintf("%.1f", e.contrast) == fmt.Sprintf("%.1f", limit)
+		}, nil
+	}
+
+	key, value, found := strings.Cut(field, ":")
+	if !found {
+		return nil, nil
+	}
+	switch key {
+	case "hue":
+		if !slices.Contains(palette.HueNames, value) {
+			return nil, fmt.Errorf("unknown hue %q, expected one of %s", value, strings.Join(palette.HueNames, ", "))
+		}
+		return func(e *indexEntry) bool { return e.hue == value }, nil
+	case "source":
+		return func(e *indexEntry) bool { return strings.ToLower(e.theme.Source) == value }, nil
+	case "bg", "fg":
+		return nil, fmt.Errorf("invalid color term %q, expected e.g. %s:#1e1e2e~10", field, key)
+	}
+	return nil, fmt.Errorf("unknown filter %q", key)
+}
+
+// QueryIndex holds the palette attributes of a theme list which queries are
+// evaluated against, so that they are computed once rather than per keystroke.
+type QueryIndex struct {
+	entries []indexEntry
+}
+
+type indexEntry struct {
+	theme    ThemeData
+	hue      string
+	contrast float64
+}
+
+// NewQueryIndex indexes themes.
+func NewQueryIndex(themes []ThemeData) *QueryIndex {
+	x := &QueryIndex{entries: make([]indexEntry, len(themes))}
+	for i, theme := range themes {
+		x.entries[i].theme = theme
+		if theme.Palette != nil {
+			x.entries[i].hue = theme.Palette.Background.Hue()
+			x.entries[i].contrast = palette.ContrastRatio(theme.Palette.Foreground, theme.Palette.Background)
+		}
+	}
+	return x
+}
+
+// Match returns the indices of the themes matching every palette term of q.
+// The name words of q are left to the caller.
+func (x *QueryIndex) Match(q Query) []int {
+	var matches []int
+	for i := range x.entries {
+		ok := true
+		for _, term := range q.terms {
+			if !term(&x.entries[i]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// Filter returns the themes matching q. Unlike the picker, which fuzzy
+// matches the name words, every name word must be part of the theme's name.
+func (x *QueryIndex) Filter(q Query) []ThemeData {
+	words := strings.Fields(strings.ToLower(q.Name))
+	var themes []ThemeData
+	for _, i := range x.Match(q) {
+		theme := x.entries[i].theme
+		name := strings.ToLower(theme.Name)
+		ok := true
+		for _, word := range words {
+			if !strings.Contains(name, word) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			themes = append(themes, theme)
+		}
+	}
+	return themes
+}
@@ -0,0 +1,93 @@
+package install_themes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	configloader "goalacritty_themes/config"
+)
+
+// queryThemes returns a theme list with a repository theme per hue and a work source
+func queryThemes(t *testing.T) []ThemeData {
+	themesDir := t.TempDir()
+	workDir := t.TempDir()
+	err := os.Mkdir(filepath.Join(themesDir, "themes"), os.ModePerm)
+	assert.NoError(t, err)
+	writeTheme(t, filepath.Join(themesDir, "themes"), "catppuccin", "#1e1e2e", "#cdd6f4")
+	writeTheme(t, filepath.Join(themesDir, "themes"), "gruvbox_dark", "#282828", "#ebdbb2")
+	writeTheme(t, filepath.Join(themesDir, "themes"), "solarized_light", "#fdf6e3", "#657b83")
+	writeTheme(t, workDir, "prod", "#3b0000", "#ffffff")
+	// Sources which do not exist are skipped
+	mockConfig := configloader.Config{Sources: map[string]string{"work": workDir, "gone": filepath.Join(workDir, "gone")}}
+	mockConfig.Paths.ThemesDirectory = themesDir
+
+	themes, err := GetThemeDataNames(mockConfig)
+	assert.NoError(t, err)
+	assert.Len(t, themes, 4)
+	return themes
+}
+
+// TestQuery tests the palette terms of the filter query language
+func TestQuery(t *testing.T) {
+	themes := queryThemes(t)
+	index := NewQueryIndex(themes)
+
+	tests := []struct {
+		query    string
+		expected []string
+		name     string
+	}{
+		{"dark", []string{"catppuccin", "gruvbox_dark", "prod"}, ""},
+		{"light", []string{"solarized_light"}, ""},
+		{"bg:#1e1e2e", []string{"catppuccin"}, ""},
+		{"bg:1f1f2f~1", []string{"catppuccin"}, ""},
+		{"bg:#1e1e2e~6", []string{"catppuccin", "gruvbox_dark"}, ""},
+		{"fg:#ffffff~1", []string{"prod"}, ""},
+		{"hue:blue", []string{"catppuccin"}, ""},
+		{"hue:grey", []string{"gruvbox_dark"}, ""},
+		{"contrast>10", []string{"catppuccin", "gruvbox_dark", "prod"}, ""},
+		{"contrast<5", []string{"solarized_light"}, ""},
+		{"source:work", []string{"prod"}, ""},
+		{"source:default light", []string{"solarized_light"}, ""},
+		{"dark gruv", []string{"catppuccin", "gruvbox_dark", "prod"}, "gruv"},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		assert.NoError(t, err, tt.query)
+		var names []string
+		for _, i := range index.Match(q) {
+			names = append(names, themes[i].Name)
+		}
+		assert.Equal(t, tt.expected, names, "Unexpected matches for %q", tt.query)
+		assert.Equal(t, tt.name, q.Name, "Unexpected name words for %q", tt.query)
+	}
+}
+
+// TestParseQueryErrors tests that malformed terms are rejected
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{"hue:teal", "bg:#12", "fg:red", "size:large"} {
+		_, err := ParseQuery(query)
+		assert.Error(t, err, "Expected an error for %q", query)
+	}
+}
+
+// TestQueryFilter tests that Filter also requires the name words
+func TestQueryFilter(t *testing.T) {
+	index := NewQueryIndex(queryThemes(t))
+
+	for query, expected := range map[string][]string{
+		"dark":          {"catppuccin", "gruvbox_dark", "prod"},
+		"dark GRUV":     {"gruvbox_dark"},
+		"light gruvbox": nil,
+	} {
+		q, err := ParseQuery(query)
+		assert.NoError(t, err, query)
+		var names []string
+		for _, theme := range index.Filter(q) {
+			names = append(names, theme.Name)
+		}
+		assert.Equal(t, expected, names, "Unexpected themes for %q", query)
+	}
+}
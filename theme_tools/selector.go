@@ -0,0 +1,108 @@
+package install_themes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	configloader "goalacritty_themes/config"
+)
+
+// ErrNoMatch is returned when no installed theme satisfies a Selector.
+var ErrNoMatch = errors.New("no theme matches")
+
+// Selector names a theme, or gives a filter query (see ParseQuery) to choose
+// one from. Config sections which apply themes on their own use it.
+type Selector struct {
+	Theme  string // the theme's name, or empty if Filter is used
+	Filter string
+	query  Query
+}
+
+// NewSelector checks that exactly one of theme and filter is given and
+// parses the filter.
+func NewSelector(theme, filter string) (Selector, error) {
+	if (theme == "") == (filter == "") {
+		return Selector{}, errors.New("needs either a theme or a filter")
+	}
+	if err := checkThemeName(theme); err != nil {
+		return Selector{}, err
+	}
+	q, err := ParseQuery(filter)
+	if err != nil {
+		return Selector{}, err
+	}
+	return Selector{Theme: theme, Filter: filter, query: q}, nil
+}
+
+func (s Selector) String() string {
+	if s.Theme != "" {
+		return s.Theme
+	}
+	return "filter " + s.Filter
+}
+
+// Pick returns the selected theme. For a filter the current theme is kept if
+// it matches, otherwise the first matching theme is chosen.
+func (s Selector) Pick(themes []ThemeData, current string) (ThemeData, error) {
+	if s.Theme != "" {
+		for _, theme := range themes {
+			if theme.Name == s.Theme {
+				return theme, nil
+			}
+		}
+		return ThemeData{}, fmt.Errorf("%w: %s is not installed", ErrNoMatch, s.Theme)
+	}
+	matches := NewQueryIndex(themes).Filter(s.query)
+	if len(matches) == 0 {
+		return ThemeData{}, fmt.Errorf("%w %q", ErrNoMatch, s.Filter)
+	}
+	for _, theme := range matches {
+		if theme.Name == current {
+			return theme, nil
+		}
+	}
+	return matches[0], nil
+}
+
+// Find picks the selected theme among the installed themes. A named theme is
+// looked up directly instead of reading every theme, which keeps commands run
+// by shell hooks fast.
+func (s Selector) Find(config configloader.Config, current string) (ThemeData, error) {
+	if s.Theme == "" {
+		themes, err := GetThemeDataNames(config)
+		if err != nil {
+			return ThemeData{}, err
+		}
+		return s.Pick(themes, current)
+	}
+	// Names come from files like .alacritty-theme of any checkout, which
+	// must not reach theme files elsewhere
+	if err := checkThemeName(s.Theme); err != nil {
+		return ThemeData{}, err
+	}
+	sources := append([]string{DefaultSource}, sourceNames(config)...)
+	for i, dir := range ThemeDirectories(config) {
+		path := filepath.Join(dir, s.Theme+".toml")
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		theme := ThemeData{Name: s.Theme, FullPath: path, Source: sources[i]}
+		entry := indexThemeFile(path, info, catalogEntry{})
+		theme.Palette, theme.Class, theme.ClassConfidence, theme.Readability = entry.Palette, entry.Class, entry.ClassConfidence, entry.Readability
+		return theme, nil
+	}
+	return ThemeData{}, fmt.Errorf("%w: %s is not installed", ErrNoMatch, s.Theme)
+}
+
+// checkThemeName rejects theme names which are paths rather than the name of
+// a file in a theme directory.
+func checkThemeName(name string) error {
+	if name != "" && (filepath.Base(name) != name || name == ".." || strings.ContainsAny(name, `/\`)) {
+		return fmt.Errorf("invalid theme name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package install_themes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	configloader "goalacritty_themes/config"
+	"goalacritty_themes/palette"
+)
+
+// TestNewSelector tests that a selector needs exactly one of a theme and a valid filter
+func TestNewSelector(t *testing.T) {
+	s, err := NewSelector("nord", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "nord", s.String())
+	s, err = NewSelector("", "dark")
+	assert.NoError(t, err)
+	assert.Equal(t, "filter dark", s.String())
+
+	for _, invalid := range [][2]string{{"", ""}, {"nord", "dark"}, {"", "hue:teal"}, {"../../nord", ""}, {"..", ""}, {"/etc/nord", ""}, {`..\nord`, ""}} {
+		_, err := NewSelector(invalid[0], invalid[1])
+		assert.Error(t, err, "Expected an error for %q", invalid)
+	}
+}
+
+// TestSelectorPick tests choosing a theme by name or by filter
+func TestSelectorPick(t *testing.T) {
+	themes := queryThemes(t)
+
+	s, _ := NewSelector("prod", "")
+	theme, err := s.Pick(themes, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", theme.Name)
+
+	s, _ = NewSelector("", "dark")
+	theme, err = s.Pick(themes, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "catppuccin", theme.Name, "The first matching theme should be chosen")
+	theme, err = s.Pick(themes, "gruvbox_dark")
+	assert.NoError(t, err)
+	assert.Equal(t, "gruvbox_dark", theme.Name, "A matching current theme should be kept")
+
+	for _, s := range []Selector{{Theme: "dracula"}, {Filter: "light dark"}} {
+		s, _ = NewSelector(s.Theme, s.Filter)
+		_, err = s.Pick(themes, "")
+		assert.ErrorIs(t, err, ErrNoMatch)
+	}
+}
+
+// TestSelectorFind tests looking up the selected theme in the configured directories
+func TestSelectorFind(t *testing.T) {
+	themesDir := t.TempDir()
+	workDir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(themesDir, "themes"), os.ModePerm))
+	writeTheme(t, filepath.Join(themesDir, "themes"), "gruvbox_dark", "#282828", "#ebdbb2")
+	writeTheme(t, workDir, "prod", "#3b0000", "#ffffff")
+	config := configloader.Config{Sources: map[string]string{"work": workDir}}
+	config.Paths.ThemesDirectory = themesDir
+
+	s, _ := NewSelector("prod", "")
+	theme, err := s.Find(config, "")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(workDir, "prod.toml"), theme.FullPath)
+	assert.Equal(t, "work", theme.Source)
+	assert.Equal(t, palette.Dark, theme.Class)
+	assert.NotNil(t, theme.Palette)
+
+	s, _ = NewSelector("", "light")
+	_, err = s.Find(config, "")
+	assert.ErrorIs(t, err, ErrNoMatch)
+	s, _ = NewSelector("", "dark")
+	theme, err = s.Find(config, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", theme.Name)
+
+	s, _ = NewSelector("nord", "")
+	_, err = s.Find(config, "")
+	assert.ErrorIs(t, err, ErrNoMatch)
+
+	// A theme outside of the theme directories is not reached
+	writeTheme(t, themesDir, "outside", "#000000", "#ffffff")
+	_, err = Selector{Theme: "../outside"}.Find(config, "")
+	assert.EqualError(t, err, `invalid theme name "../outside"`)
+}
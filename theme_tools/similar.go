@@ -0,0 +1,62 @@
+package install_themes
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"goalacritty_themes/palette"
+)
+
+// Match is a theme ranked by its palette distance to another theme.
+type Match struct {
+	Theme    ThemeData
+	Distance float64
+}
+
+// SimilarityIndex ranks themes by palette distance. Rankings are cached per
+// theme, so asking for the same theme again is instant. An index belongs to
+// one theme list; build a new one when the list is reloaded.
+type SimilarityIndex struct {
+	themes   []ThemeData
+	mu       sync.Mutex
+	rankings map[string][]Match
+}
+
+// NewSimilarityIndex returns an index over themes.
+func NewSimilarityIndex(themes []ThemeData) *SimilarityIndex {
+	return &SimilarityIndex{themes: themes, rankings: map[string][]Match{}}
+}
+
+// Similar returns every other parsable theme, closest to the named theme first.
+func (s *SimilarityIndex) Similar(name string) ([]Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ranking, ok := s.rankings[name]; ok {
+		return ranking, nil
+	}
+
+	var target *palette.Palette
+	for _, theme := range s.themes {
+		if theme.Name == name {
+			target = theme.Palette
+			if target == nil {
+				return nil, fmt.Errorf("%s: theme could not be parsed", name)
+			}
+		}
+	}
+	if target == nil {
+		return nil, &ThemeError{Theme: name, Err: ErrThemeMissing}
+	}
+
+	var ranking []Match
+	for _, theme := range s.themes {
+		if theme.Name == name || theme.Palette == nil {
+			continue
+		}
+		ranking = append(ranking, Match{Theme: theme, Distance: palette.Distance(*target, *theme.Palette)})
+	}
+	sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].Distance < ranking[j].Distance })
+	s.rankings[name] = ranking
+	return ranking, nil
+}
@@ -0,0 +1,50 @@
+package install_themes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	configloader "goalacritty_themes/config"
+)
+
+// TestSimilar tests that themes are ranked by palette distance
+func TestSimilar(t *testing.T) {
+	themesDir := t.TempDir()
+	err := os.Mkdir(filepath.Join(themesDir, "themes"), os.ModePerm)
+	assert.NoError(t, err)
+	writeTheme(t, filepath.Join(themesDir, "themes"), "night", "#1e1e2e", "#cdd6f4")
+	writeTheme(t, filepath.Join(themesDir, "themes"), "midnight", "#181825", "#bac2de")
+	writeTheme(t, filepath.Join(themesDir, "themes"), "dusk", "#45475a", "#f5e0dc")
+	writeTheme(t, filepath.Join(themesDir, "themes"), "paper", "#eeeeee", "#444444")
+	_, err = os.Create(filepath.Join(themesDir, "themes", "broken.toml"))
+	assert.NoError(t, err)
+
+	mockConfig := configloader.Config{}
+	mockConfig.Paths.ThemesDirectory = themesDir
+	themes, err := GetThemeDataNames(mockConfig)
+	assert.NoError(t, err)
+
+	index := NewSimilarityIndex(themes)
+	matches, err := index.Similar("night")
+	assert.NoError(t, err)
+
+	var names []string
+	for _, m := range matches {
+		names = append(names, m.Theme.Name)
+	}
+	assert.Equal(t, []string{"midnight", "dusk", "paper"}, names, "Expected the closest palettes first, without the theme itself or unparsable themes")
+	assert.Less(t, matches[0].Distance, matches[2].Distance)
+
+	// The second query is answered from the cache
+	again, err := index.Similar("night")
+	assert.NoError(t, err)
+	assert.Equal(t, &matches[0], &again[0])
+
+	_, err = index.Similar("missing")
+	assert.True(t, errors.Is(err, ErrThemeMissing))
+	_, err = index.Similar("broken")
+	assert.Error(t, err)
+}
@@ -1,12 +1,14 @@
 package install_themes
 
 import configloader "goalacritty_themes/config"
+import "goalacritty_themes/palette"
 import (
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"syscall"
 )
@@ -43,10 +45,18 @@ func InstallThemes(config configloader.Config) error {
 	if err := cloneRepoCmd.Run(); err != nil {
 		return err
 	}
-	fmt.Println("Alacritty-theme repository cloned successfully")
   return nil
 }
 
+// ReinstallThemes removes the themes directory and clones the repository again.
+// It is the recovery action for ErrRepoCorrupt.
+func ReinstallThemes(config configloader.Config) error {
+	if err := os.RemoveAll(os.ExpandEnv(config.Paths.ThemesDirectory)); err != nil {
+		return fmt.Errorf("error removing themes directory: %w", err)
+	}
+	return InstallThemes(config)
+}
+
 func handleExecError(err error) {
 	if exitError, ok := err.(*exec.ExitError); ok {
 		if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 128 {
@@ -61,48 +71,147 @@ func handleExecError(err error) {
 type ThemeData struct {
 	Name     string
 	FullPath string
+	// Source is DefaultSource or the name of the source in config.Sources
+	Source string
+	// Palette is nil when the theme file could not be parsed
+	Palette *palette.Palette
+	// Class is the dark/light classification of the theme's background, with
+	// ClassConfidence between 0 (mid grey) and 1 (black or white)
+	Class           palette.Class
+	ClassConfidence float64
+	// Readability is the score of the palette's audit, from 0 to 100
+	Readability float64
+}
+
+// FilterByClass returns the themes of the given class. Unknown keeps every theme.
+func FilterByClass(themes []ThemeData, class palette.Class) []ThemeData {
+	if class == palette.Unknown {
+		return themes
+	}
+	var filtered []ThemeData
+	for _, theme := range themes {
+		if theme.Class == class {
+			filtered = append(filtered, theme)
+		}
+	}
+	return filtered
 }
 
-// GetThemeDataNames reads the names and full paths of files in the config.Paths.Themes directory
+// GetThemeDataNames reads the names and full paths of files in the config.Paths.Themes directory,
+// followed by the theme files of the additional sources in config.Sources
 func GetThemeDataNames(config configloader.Config) ([]ThemeData, error) {
 	dir := os.ExpandEnv(config.Paths.ThemesDirectory + "/themes/")
-	files, err := os.ReadDir(dir)
+	themeFiles, err := readThemeDir(dir, DefaultSource)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s does not exist", ErrRepoCorrupt, dir)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error reading directory: %w", err)
 	}
+	if len(themeFiles) == 0 {
+		return nil, fmt.Errorf("%w: no themes found in %s", ErrRepoCorrupt, dir)
+	}
+
+	for _, source := range sourceNames(config) {
+		themes, err := readThemeDir(config.Sources[source], source)
+		if os.IsNotExist(err) {
+			// A source may live on a drive which is not always mounted
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading source %s: %w", source, err)
+		}
+		themeFiles = append(themeFiles, themes...)
+	}
+	// Unparsable themes are kept with an Unknown class, so that they can still be selected
+	loadPalettes(themeFiles)
+	return themeFiles, nil
+}
+
+// DefaultSource is the source name of the themes in the cloned repository.
+const DefaultSource = "default"
+
+// sourceNames returns the names of the additional theme sources in a stable order.
+func sourceNames(config configloader.Config) []string {
+	var names []string
+	for name := range config.Sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ThemeDirectories returns the directories GetThemeDataNames reads themes from.
+func ThemeDirectories(config configloader.Config) []string {
+	dirs := []string{os.ExpandEnv(config.Paths.ThemesDirectory + "/themes/")}
+	for _, source := range sourceNames(config) {
+		dirs = append(dirs, config.Sources[source])
+	}
+	return dirs
+}
+
+// readThemeDir reads the theme files in dir.
+func readThemeDir(dir, source string) ([]ThemeData, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
 
 	var themeFiles []ThemeData
 	for _, file := range files {
 		if !file.IsDir() {
 			fullPath := filepath.Join(dir, file.Name())
-			themeFiles = append(themeFiles, ThemeData{
+			theme := ThemeData{
 				Name:     strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())),
 				FullPath: fullPath,
-			})
+				Source:   source,
+			}
+			themeFiles = append(themeFiles, theme)
 		}
 	}
 	return themeFiles, nil
 }
 
+// themeDirsPattern matches the directory part of any theme file path the
+// alacritty config may import: the repository's themes or one of the sources.
+func themeDirsPattern(config configloader.Config) string {
+	dirs := []string{regexp.QuoteMeta(config.Paths.ThemesDirectory + "/themes/")}
+	for _, source := range sourceNames(config) {
+		dirs = append(dirs, regexp.QuoteMeta(filepath.Clean(config.Sources[source])+"/"))
+	}
+	return "(?:" + strings.Join(dirs, "|") + ")"
+}
+
+// GetCurrentTheme returns the theme imported by the alacritty config. Name and
+// FullPath are empty if the config does not import any known theme.
 func GetCurrentTheme(config configloader.Config) (*ThemeData, error) {
 
 	alacrittyConfigPath := config.Paths.AlacrittyConfigPath
-	expandedThemesDirectory := config.Paths.ThemesDirectory
-	themePathPattern := regexp.QuoteMeta(expandedThemesDirectory+"/themes/") + `(\w+)\.toml`
+	themePathPattern := themeDirsPattern(config) + `([^"/]+)\.toml`
 
 	// Read the Alacritty config file
 	content, err := os.ReadFile(alacrittyConfigPath) // Use expanded path
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrConfigNotFound, alacrittyConfigPath)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Replace the old theme path with the new one using a regex
+	// Find the imported theme path, the file name being the theme name
 	re := regexp.MustCompile(themePathPattern)
-	currentThemePath := re.FindString(string(content))
-	return &ThemeData{
-		Name:     currentThemePath,
-		FullPath: currentThemePath,
-	}, nil
+	match := re.FindStringSubmatch(string(content))
+	if match == nil {
+		return &ThemeData{}, nil
+	}
+	currentTheme := &ThemeData{
+		Name:     match[1],
+		FullPath: match[0],
+	}
+	if err := CheckTheme(*currentTheme); err != nil {
+		return nil, err
+	}
+	return currentTheme, nil
 }
 
 
@@ -111,7 +220,7 @@ func InitAlacrittyConfig(config configloader.Config, theme ThemeData) error {
 	// Read the existing Alacritty config file
   alacrittyConfigPath := config.Paths.AlacrittyConfigPath
   themePath := theme.FullPath
-	themePattern := regexp.QuoteMeta("/themes/") + `(\w+)\.toml`
+	themePattern := regexp.QuoteMeta("/themes/") + `([^"/]+)\.toml`
 
  // Check if the Alacritty config file exists
   if _, err := os.Stat(alacrittyConfigPath); os.IsNotExist(err) {
@@ -152,23 +261,26 @@ func InitAlacrittyConfig(config configloader.Config, theme ThemeData) error {
 
 func UpdateAlacrittyConfigFile(config configloader.Config, td ThemeData) error {
 	alacrittyConfigPath := config.Paths.AlacrittyConfigPath
-	expandedThemesDirectory := config.Paths.ThemesDirectory
 	newThemePath := td.FullPath
 
-	// Construct the old and new theme paths to search and replace
-	themePattern := regexp.QuoteMeta(expandedThemesDirectory + "/themes/") + `[^\"]+\.toml`
+	// Match a theme file directly in one of the theme directories, so that
+	// other imports below a source directory are left alone
+	themePattern := themeDirsPattern(config) + `[^"/]+\.toml`
 
 	// Read the Alacritty config file
 	content, err := os.ReadFile(alacrittyConfigPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrConfigNotFound, alacrittyConfigPath)
+	}
 	if err != nil {
 		return err
 	}
 
 	// Check if the old theme path exists and replace it
 	re := regexp.MustCompile(themePattern)
-	if re.MatchString(string(content)) {
-		// Replace the old theme path with the new one
-		modifiedContent := re.ReplaceAllString(string(content), newThemePath)
+	if loc := re.FindStringIndex(string(content)); loc != nil {
+		// Replace the theme import, the one GetCurrentTheme reports
+		modifiedContent := string(content[:loc[0]]) + newThemePath + string(content[loc[1]:])
 		return os.WriteFile(alacrittyConfigPath, []byte(modifiedContent), 0644)
 	}
 
@@ -1,13 +1,15 @@
 package install_themes
 
 import (
-  "fmt"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	configloader "goalacritty_themes/config"
+	"goalacritty_themes/palette"
 )
 
 // TestGetThemeDataNames tests the GetThemeDataNames function
@@ -147,3 +149,135 @@ func TestUpdateAlacrittyConfigFile(t *testing.T) {
 
 }
 
+
+// TestGetThemeDataNamesMissingDirectory checks that a repository without a themes directory is reported as corrupt
+func TestGetThemeDataNamesMissingDirectory(t *testing.T) {
+	themesDir, err := os.MkdirTemp("", "test_get_theme_data")
+	assert.NoError(t, err)
+	defer os.RemoveAll(themesDir)
+
+	mockConfig := configloader.Config{}
+	mockConfig.Paths.ThemesDirectory = themesDir
+
+	_, err = GetThemeDataNames(mockConfig)
+	assert.True(t, errors.Is(err, ErrRepoCorrupt), "Expected ErrRepoCorrupt, got %v", err)
+
+	// An empty themes directory is just as unusable
+	err = os.Mkdir(filepath.Join(themesDir, "themes"), os.ModePerm)
+	assert.NoError(t, err)
+	_, err = GetThemeDataNames(mockConfig)
+	assert.True(t, errors.Is(err, ErrRepoCorrupt), "Expected ErrRepoCorrupt, got %v", err)
+}
+
+// TestConfigNotFound checks that a missing alacritty config is reported as ErrConfigNotFound
+func TestConfigNotFound(t *testing.T) {
+	mockConfig := configloader.Config{}
+	mockConfig.Paths.ThemesDirectory = "/mock"
+	mockConfig.Paths.AlacrittyConfigPath = filepath.Join(t.TempDir(), "alacritty.toml")
+
+	_, err := GetCurrentTheme(mockConfig)
+	assert.True(t, errors.Is(err, ErrConfigNotFound), "Expected ErrConfigNotFound, got %v", err)
+
+	err = UpdateAlacrittyConfigFile(mockConfig, ThemeData{Name: "dark-theme", FullPath: "/mock/themes/dark-theme.toml"})
+	assert.True(t, errors.Is(err, ErrConfigNotFound), "Expected ErrConfigNotFound, got %v", err)
+}
+
+// TestCheckTheme checks that CheckTheme reports missing theme files
+func TestCheckTheme(t *testing.T) {
+	themePath := filepath.Join(t.TempDir(), "dark-theme.toml")
+	theme := ThemeData{Name: "dark-theme", FullPath: themePath}
+
+	err := CheckTheme(theme)
+	assert.True(t, errors.Is(err, ErrThemeMissing), "Expected ErrThemeMissing, got %v", err)
+	assert.Contains(t, err.Error(), "dark-theme")
+
+	assert.NoError(t, os.WriteFile(themePath, nil, 0644))
+	assert.NoError(t, CheckTheme(theme))
+}
+
+// writeTheme writes a minimal theme file with the given primary colors
+func writeTheme(t *testing.T, dir, name, background, foreground string) {
+	content := "[colors.primary]\nbackground = '" + background + "'\nforeground = '" + foreground + "'\n"
+	err := os.WriteFile(filepath.Join(dir, name+".toml"), []byte(content), 0644)
+	assert.NoError(t, err)
+}
+
+// TestGetThemeDataNamesClassifies tests that themes are parsed and classified as dark or light
+func TestGetThemeDataNamesClassifies(t *testing.T) {
+	themesDir := t.TempDir()
+	err := os.Mkdir(filepath.Join(themesDir, "themes"), os.ModePerm)
+	assert.NoError(t, err)
+	writeTheme(t, filepath.Join(themesDir, "themes"), "night", "#1e1e2e", "#cdd6f4")
+	writeTheme(t, filepath.Join(themesDir, "themes"), "paper", "#eeeeee", "#444444")
+	_, err = os.Create(filepath.Join(themesDir, "themes", "broken.toml"))
+	assert.NoError(t, err)
+
+	mockConfig := configloader.Config{}
+	mockConfig.Paths.ThemesDirectory = themesDir
+
+	themes, err := GetThemeDataNames(mockConfig)
+	assert.NoError(t, err)
+	assert.Len(t, themes, 3)
+
+	classes := map[string]palette.Class{}
+	for _, theme := range themes {
+		classes[theme.Name] = theme.Class
+	}
+	assert.Equal(t, palette.Unknown, classes["broken"], "Unparsable themes should stay unclassified")
+	assert.Equal(t, palette.Dark, classes["night"])
+	assert.Equal(t, palette.Light, classes["paper"])
+
+	dark := FilterByClass(themes, palette.Dark)
+	assert.Len(t, dark, 1)
+	assert.Equal(t, "night", dark[0].Name)
+	assert.Len(t, FilterByClass(themes, palette.Unknown), 3, "Unknown should not filter anything")
+}
+
+// TestGetCurrentTheme tests that the name and path of the imported theme are returned, hyphens included
+func TestGetCurrentTheme(t *testing.T) {
+	themesDir := t.TempDir()
+	err := os.Mkdir(filepath.Join(themesDir, "themes"), os.ModePerm)
+	assert.NoError(t, err)
+	writeTheme(t, filepath.Join(themesDir, "themes"), "tokyo-night_storm", "#24283b", "#c0caf5")
+
+	mockConfig := configloader.Config{}
+	mockConfig.Paths.ThemesDirectory = themesDir
+	mockConfig.Paths.AlacrittyConfigPath = filepath.Join(t.TempDir(), "alacritty.toml")
+
+	// A config without a theme import has no current theme
+	assert.NoError(t, os.WriteFile(mockConfig.Paths.AlacrittyConfigPath, []byte("[font]\nsize = 12\n"), 0644))
+	current, err := GetCurrentTheme(mockConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, ThemeData{}, *current)
+
+	themePath := filepath.Join(themesDir, "themes", "tokyo-night_storm.toml")
+	assert.NoError(t, InitAlacrittyConfig(mockConfig, ThemeData{Name: "tokyo-night_storm", FullPath: themePath}))
+	current, err = GetCurrentTheme(mockConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "tokyo-night_storm", current.Name)
+	assert.Equal(t, themePath, current.FullPath)
+
+	// Initialising again must not add a second import
+	assert.NoError(t, InitAlacrittyConfig(mockConfig, ThemeData{Name: "tokyo-night_storm", FullPath: themePath}))
+	content, err := os.ReadFile(mockConfig.Paths.AlacrittyConfigPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "import"))
+}
+
+// TestUpdateAlacrittyConfigFileSources tests that only the theme import is
+// replaced when a source is a directory holding other imported files
+func TestUpdateAlacrittyConfigFileSources(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "alacritty.toml")
+	var config configloader.Config
+	config.Paths.ThemesDirectory = filepath.Join(dir, "repo")
+	config.Paths.AlacrittyConfigPath = configPath
+	config.Sources = map[string]string{"local": dir}
+
+	content := "import = [\n\"" + dir + "/keys.toml\",\n\"" + dir + "/own.toml\",\n\"" + dir + "/extra/fonts.toml\"\n]\n"
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+	assert.NoError(t, UpdateAlacrittyConfigFile(config, ThemeData{Name: "nord", FullPath: dir + "/repo/themes/nord.toml"}))
+	updated, err := os.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Replace(content, dir+"/keys.toml", dir+"/repo/themes/nord.toml", 1), string(updated))
+}
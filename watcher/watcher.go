@@ -0,0 +1,220 @@
+package watcher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Kind tells what happened to a watched file.
+type Kind int
+
+const (
+	ThemeAdded Kind = iota
+	ThemeRemoved
+	ThemeChanged
+	// ConfigChanged is only reported when the imports of the alacritty
+	// config change, not for every write to it
+	ConfigChanged
+)
+
+func (k Kind) String() string {
+	switch k {
+	case ThemeAdded:
+		return "added"
+	case ThemeRemoved:
+		return "removed"
+	case ThemeChanged:
+		return "changed"
+	}
+	return "config changed"
+}
+
+// Event is a change to a theme file or to the alacritty config.
+type Event struct {
+	Kind Kind
+	Path string
+}
+
+// debounce is how long the watcher waits for more changes before reporting a
+// batch. Editors and git touch files several times in quick succession.
+const debounce = 150 * time.Millisecond
+
+// importRe matches the import list of an alacritty config, at the top level
+// or in the [general] section.
+var importRe = regexp.MustCompile(`(?m)^\s*import\s*=\s*\[[^\]]*\]`)
+
+// Watcher reports changes to the theme directories and to the imports of the
+// alacritty config.
+type Watcher struct {
+	fs         *fsnotify.Watcher
+	themeDirs  map[string]bool
+	configPath string
+	imports    []byte
+
+	events chan []Event
+	errors chan error
+
+	mu      sync.Mutex
+	pending map[string]Kind
+	timer   *time.Timer
+	closed  chan struct{}
+}
+
+// New starts watching themeDirs and configPath. Theme directories which do not
+// exist are skipped.
+func New(themeDirs []string, configPath string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		fs:         fsw,
+		themeDirs:  map[string]bool{},
+		configPath: filepath.Clean(configPath),
+		events:     make(chan []Event),
+		errors:     make(chan error),
+		pending:    map[string]Kind{},
+		closed:     make(chan struct{}),
+	}
+	for _, dir := range themeDirs {
+		dir = filepath.Clean(dir)
+		if err := fsw.Add(dir); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			fsw.Close()
+			return nil, err
+		}
+		w.themeDirs[dir] = true
+	}
+	// Editors often save by writing a new file and renaming it, which a watch
+	// on the file itself would not survive, so the directory is watched
+	if err := fsw.Add(filepath.Dir(w.configPath)); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	w.imports = w.readImports()
+	go w.run()
+	return w, nil
+}
+
+// Events returns the channel on which batches of changes are delivered.
+func (w *Watcher) Events() <-chan []Event { return w.events }
+
+// Errors returns the channel on which watch errors are delivered.
+func (w *Watcher) Errors() <-chan error { return w.errors }
+
+// Close stops the watcher. The event channel is not closed, so readers must
+// stop reading themselves.
+func (w *Watcher) Close() error {
+	select {
+	case <-w.closed:
+		return nil
+	default:
+	}
+	close(w.closed)
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	return w.fs.Close()
+}
+
+func (w *Watcher) run() {
+	for {
+		select {
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			w.handle(ev)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			select {
+			case w.errors <- err:
+			case <-w.closed:
+				return
+			}
+		}
+	}
+}
+
+// handle records ev and (re)starts the debounce timer.
+func (w *Watcher) handle(ev fsnotify.Event) {
+	path := filepath.Clean(ev.Name)
+	var kind Kind
+	switch {
+	case path == w.configPath:
+		kind = ConfigChanged
+	case w.themeDirs[filepath.Dir(path)] && strings.HasSuffix(path, ".toml"):
+		switch {
+		case ev.Has(fsnotify.Create):
+			kind = ThemeAdded
+		case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+			kind = ThemeRemoved
+		case ev.Has(fsnotify.Write):
+			kind = ThemeChanged
+		default:
+			return
+		}
+	default:
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if previous, ok := w.pending[path]; ok && previous == ThemeAdded && kind == ThemeChanged {
+		// Writing a new file is still adding it
+		kind = ThemeAdded
+	}
+	w.pending[path] = kind
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(debounce, w.flush)
+}
+
+// flush delivers the pending changes as one batch.
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	var batch []Event
+	for path, kind := range w.pending {
+		if kind == ConfigChanged {
+			imports := w.readImports()
+			if bytes.Equal(imports, w.imports) {
+				continue
+			}
+			w.imports = imports
+		}
+		batch = append(batch, Event{Kind: kind, Path: path})
+	}
+	w.pending = map[string]Kind{}
+	w.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+	select {
+	case w.events <- batch:
+	case <-w.closed:
+	}
+}
+
+// readImports returns the import lists of the alacritty config.
+func (w *Watcher) readImports() []byte {
+	content, err := os.ReadFile(w.configPath)
+	if err != nil {
+		return nil
+	}
+	return bytes.Join(importRe.FindAll(content, -1), []byte("\n"))
+}
@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nextBatch waits for the next batch of events, failing the test after a timeout.
+func nextBatch(t *testing.T, w *Watcher) []Event {
+	select {
+	case batch := <-w.Events():
+		return batch
+	case err := <-w.Errors():
+		t.Fatalf("Unexpected watch error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for events")
+	}
+	return nil
+}
+
+// assertNoBatch checks that no events are delivered for a while.
+func assertNoBatch(t *testing.T, w *Watcher) {
+	select {
+	case batch := <-w.Events():
+		t.Fatalf("Expected no events, got %v", batch)
+	case <-time.After(3 * debounce):
+	}
+}
+
+// TestWatchThemes tests that theme files being added, changed and removed are reported
+func TestWatchThemes(t *testing.T) {
+	themesDir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "alacritty.toml")
+	w, err := New([]string{themesDir, filepath.Join(themesDir, "missing")}, configPath)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	themePath := filepath.Join(themesDir, "night.toml")
+	assert.NoError(t, os.WriteFile(themePath, []byte("[colors.primary]\n"), 0644))
+	assert.Equal(t, []Event{{Kind: ThemeAdded, Path: themePath}}, nextBatch(t, w))
+
+	assert.NoError(t, os.WriteFile(themePath, []byte("[colors.primary]\nbackground = '#000000'\n"), 0644))
+	assert.Equal(t, []Event{{Kind: ThemeChanged, Path: themePath}}, nextBatch(t, w))
+
+	// Files which are not themes are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(themesDir, "README.md"), nil, 0644))
+	assertNoBatch(t, w)
+
+	assert.NoError(t, os.Remove(themePath))
+	assert.Equal(t, []Event{{Kind: ThemeRemoved, Path: themePath}}, nextBatch(t, w))
+}
+
+// TestWatchConfig tests that the config is only reported when its imports change
+func TestWatchConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "alacritty.toml")
+	assert.NoError(t, os.WriteFile(configPath, []byte("import = [\n\"/themes/a.toml\"\n]\n"), 0644))
+	w, err := New(nil, configPath)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	assert.NoError(t, os.WriteFile(configPath, []byte("import = [\n\"/themes/a.toml\"\n]\n\n[font]\nsize = 12\n"), 0644))
+	assertNoBatch(t, w)
+
+	// Saving through a rename, like most editors do, is noticed as well
+	tmp := configPath + ".tmp"
+	assert.NoError(t, os.WriteFile(tmp, []byte("import = [\n\"/themes/b.toml\"\n]\n"), 0644))
+	assert.NoError(t, os.Rename(tmp, configPath))
+	assert.Equal(t, []Event{{Kind: ConfigChanged, Path: configPath}}, nextBatch(t, w))
+}